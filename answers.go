@@ -0,0 +1,305 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Answers 应答文件内容，在 Config 的基础上增加下载源
+type Answers struct {
+	Config
+	DownloadSource string `json:"download_source"`
+}
+
+// requiredAnswerKeys 应答文件中必须提供的字段
+var requiredAnswerKeys = []string{
+	"bind_port",
+	"vhost_http_port",
+	"vhost_https_port",
+	"dashboard_port",
+	"dashboard_user",
+	"dashboard_pwd",
+	"token",
+}
+
+// FieldError 单个字段的校验错误
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Message)
+}
+
+// FieldErrors 多个字段的校验错误
+type FieldErrors []FieldError
+
+func (e FieldErrors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, fe := range e {
+		lines = append(lines, "  - "+fe.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// decodeStructuredFile 根据扩展名将 JSON/TOML/YAML 文件解析为 map
+func decodeStructuredFile(path string) (map[string]interface{}, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("读取文件失败: %v", err)
+	}
+
+	raw := make(map[string]interface{})
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		err = json.Unmarshal(content, &raw)
+	case ".toml":
+		_, err = toml.Decode(string(content), &raw)
+	case ".yaml", ".yml":
+		err = yaml.Unmarshal(content, &raw)
+	default:
+		return nil, fmt.Errorf("不支持的文件格式: %s (支持 .json, .toml, .yaml, .yml)", filepath.Ext(path))
+	}
+	if err != nil {
+		return nil, fmt.Errorf("解析文件失败: %v", err)
+	}
+	return raw, nil
+}
+
+// answerFields 返回应答文件中允许出现的字段及其对应的结构体字段
+func answerFields(v reflect.Value) map[string]reflect.Value {
+	fields := make(map[string]reflect.Value)
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous {
+			for key, value := range answerFields(v.Field(i)) {
+				fields[key] = value
+			}
+			continue
+		}
+		key := strings.Split(field.Tag.Get("json"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+		fields[key] = v.Field(i)
+	}
+	return fields
+}
+
+// loadAnswersFile 加载应答文件，返回内容以及文件中出现过的字段
+func loadAnswersFile(path string) (*Answers, map[string]bool, error) {
+	raw, err := decodeStructuredFile(path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	answers := &Answers{}
+	fields := answerFields(reflect.ValueOf(answers).Elem())
+	present := make(map[string]bool)
+	var errs FieldErrors
+
+	keys := make([]string, 0, len(raw))
+	for key := range raw {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		field, ok := fields[key]
+		if !ok {
+			errs = append(errs, FieldError{key, "未知字段"})
+			continue
+		}
+
+		// 借助 JSON 完成类型转换，保证三种格式的行为一致
+		data, err := json.Marshal(raw[key])
+		if err != nil {
+			errs = append(errs, FieldError{key, fmt.Sprintf("无法识别的值: %v", err)})
+			continue
+		}
+		target := reflect.New(field.Type())
+		if err := json.Unmarshal(data, target.Interface()); err != nil {
+			errs = append(errs, FieldError{key, fmt.Sprintf("类型错误，应为%s", kindName(field.Kind()))})
+			continue
+		}
+		field.Set(target.Elem())
+		present[key] = true
+	}
+
+	if len(errs) > 0 {
+		return nil, nil, errs
+	}
+	return answers, present, nil
+}
+
+// missingRequired 检查应答文件模式下必填项是否齐全
+func missingRequired(present map[string]bool) FieldErrors {
+	var errs FieldErrors
+	for _, key := range requiredAnswerKeys {
+		if !present[key] {
+			errs = append(errs, FieldError{key, "缺少必填项"})
+		}
+	}
+	return errs
+}
+
+// kindName 返回类型的中文描述
+func kindName(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Int, reflect.Int64:
+		return "整数"
+	case reflect.Bool:
+		return "布尔值"
+	default:
+		return "字符串"
+	}
+}
+
+// applyConfigDefaults 为未提供的字段填充与交互式安装相同的默认值
+func (fm *FrpsManager) applyConfigDefaults(present map[string]bool, serverIP string) {
+	if !present["bind_port"] {
+		fm.Config.BindPort = DefaultBindPort
+	}
+	if !present["vhost_http_port"] {
+		fm.Config.VhostHTTPPort = DefaultVhostHTTPPort
+	}
+	if !present["vhost_https_port"] {
+		fm.Config.VhostHTTPSPort = DefaultVhostHTTPSPort
+	}
+	if !present["dashboard_port"] {
+		fm.Config.DashboardPort = DefaultDashboardPort
+	}
+	if !present["dashboard_user"] {
+		fm.Config.DashboardUser = DefaultDashboardUser
+	}
+	if !present["dashboard_pwd"] {
+		fm.Config.DashboardPwd = fm.generateRandomString(8)
+	}
+	if !present["token"] {
+		fm.Config.Token = fm.generateRandomString(16)
+	}
+	if !present["subdomain_host"] {
+		fm.Config.SubdomainHost = serverIP
+	}
+	if !present["max_pool_count"] {
+		fm.Config.MaxPoolCount = DefaultMaxPoolCount
+	}
+	if !present["log_level"] {
+		fm.Config.LogLevel = DefaultLogLevel
+	}
+	if !present["log_max_days"] {
+		fm.Config.LogMaxDays = DefaultLogMaxDays
+	}
+	if !present["log_file"] {
+		fm.Config.LogFile = fm.Component.LogPath()
+	}
+	if !present["tcp_mux"] {
+		fm.Config.TCPMux = true
+	}
+	if !present["transport_protocol"] {
+		fm.Config.TransportProtocol = true
+	}
+	if fm.Config.TransportProtocol {
+		if !present["kcp_bind_port"] {
+			fm.Config.KCPBindPort = fm.Config.BindPort
+		}
+		if !present["quic_bind_port"] {
+			fm.Config.QuicBindPort = fm.Config.VhostHTTPSPort
+		}
+	}
+}
+
+// portField 待校验的端口字段
+type portField struct {
+	key   string
+	value int
+	tcp   bool
+}
+
+// validateConfig 校验配置，返回所有字段错误
+func (fm *FrpsManager) validateConfig(checkPorts bool) FieldErrors {
+	var errs FieldErrors
+	cfg := fm.Config
+
+	ports := []portField{
+		{"bind_port", cfg.BindPort, true},
+		{"vhost_http_port", cfg.VhostHTTPPort, true},
+		{"vhost_https_port", cfg.VhostHTTPSPort, true},
+		{"dashboard_port", cfg.DashboardPort, true},
+	}
+	// kcp/quic 使用 UDP，不做 TCP 占用检查
+	if cfg.TransportProtocol {
+		ports = append(ports,
+			portField{"kcp_bind_port", cfg.KCPBindPort, false},
+			portField{"quic_bind_port", cfg.QuicBindPort, false},
+		)
+	}
+
+	checked := make(map[int]bool)
+	for _, p := range ports {
+		if p.value < 1 || p.value > 65535 {
+			errs = append(errs, FieldError{p.key, fmt.Sprintf("端口 %d 超出范围 [1-65535]", p.value)})
+			continue
+		}
+		if !checkPorts || !p.tcp || checked[p.value] {
+			continue
+		}
+		checked[p.value] = true
+		if !isPortAvailable(p.value) {
+			errs = append(errs, FieldError{p.key, fmt.Sprintf("端口 %d 已被占用", p.value)})
+		}
+	}
+
+	strs := []struct {
+		key   string
+		value string
+	}{
+		{"dashboard_user", cfg.DashboardUser},
+		{"dashboard_pwd", cfg.DashboardPwd},
+		{"token", cfg.Token},
+		{"subdomain_host", cfg.SubdomainHost},
+		{"log_file", cfg.LogFile},
+	}
+	for _, s := range strs {
+		if strings.TrimSpace(s.value) == "" {
+			errs = append(errs, FieldError{s.key, "不能为空"})
+		} else if strings.ContainsAny(s.value, "\"\\\r\n") {
+			errs = append(errs, FieldError{s.key, "不能包含引号、反斜杠或换行符"})
+		}
+	}
+
+	// 移到环境变量文件的值需要能原样替换到配置中
+	if cfg.SecretsEnv {
+		for _, s := range strs {
+			if s.key == "token" || s.key == "dashboard_pwd" {
+				if err := validateEnvValue(s.value); err != nil {
+					errs = append(errs, FieldError{s.key, "使用 secrets_env 时" + err.Error()})
+				}
+			}
+		}
+	}
+
+	if cfg.MaxPoolCount < 1 || cfg.MaxPoolCount > 50 {
+		errs = append(errs, FieldError{"max_pool_count", fmt.Sprintf("%d 超出范围 [1-50]", cfg.MaxPoolCount)})
+	}
+	if cfg.LogMaxDays < 1 || cfg.LogMaxDays > 15 {
+		errs = append(errs, FieldError{"log_max_days", fmt.Sprintf("%d 超出范围 [1-15]", cfg.LogMaxDays)})
+	}
+	switch cfg.LogLevel {
+	case "trace", "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, FieldError{"log_level", fmt.Sprintf("无效的日志级别 %q (可选 trace, debug, info, warn, error)", cfg.LogLevel)})
+	}
+
+	return errs
+}
@@ -0,0 +1,108 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	_ "embed"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// embeddedInitScript 内置的 SysV 初始化脚本，离线安装时无需下载
+//
+//go:embed frps.init
+var embeddedInitScript string
+
+// archiveNamePattern frp 发布包的文件名或包内目录名，如 frp_0.61.0_linux_amd64.tar.gz
+var archiveNamePattern = regexp.MustCompile(`^frp_(\d+\.\d+\.\d+(?:-[0-9A-Za-z.]+)?)_linux_([0-9a-z]+)(?:\.tar\.gz|\.tgz)?$`)
+
+// parseArchiveName 从发布包文件名或包内目录名解析版本与架构
+func parseArchiveName(name string) (version, arch string, ok bool) {
+	match := archiveNamePattern.FindStringSubmatch(name)
+	if match == nil {
+		return "", "", false
+	}
+	return match[1], match[2], true
+}
+
+// archiveBinaryDir 返回发布包中程序 name 所在的目录
+func archiveBinaryDir(filename, name string) (string, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	gzr, err := gzip.NewReader(file)
+	if err != nil {
+		return "", err
+	}
+	defer gzr.Close()
+
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			return "", fmt.Errorf("发布包中没有 %s", name)
+		}
+		if err != nil {
+			return "", err
+		}
+		entry := path.Clean(header.Name)
+		if header.Typeflag == tar.TypeReg && path.Base(entry) == name {
+			return path.Dir(entry), nil
+		}
+	}
+}
+
+// inspectArchive 检查离线安装使用的发布包，从包内目录名或文件名识别版本与架构
+func (fm *FrpsManager) inspectArchive(opts *DownloadOptions) error {
+	fm.Colors["green"].Printf("正在检查发布包 %s...\n", opts.FromArchive)
+
+	dir, err := archiveBinaryDir(opts.FromArchive, fm.Component.Name)
+	if err != nil {
+		return fmt.Errorf("读取发布包失败: %v", err)
+	}
+	if dir == "." || strings.Contains(dir, "/") {
+		return fmt.Errorf("发布包结构不正确，%s 应位于 frp_<版本>_linux_<架构> 目录中", fm.Component.Name)
+	}
+
+	version, arch, ok := parseArchiveName(dir)
+	if !ok {
+		version, arch, ok = parseArchiveName(filepath.Base(opts.FromArchive))
+	}
+	if !ok {
+		return fmt.Errorf("无法从发布包名称或内容识别版本与架构，应为 frp_<版本>_linux_<架构>.tar.gz")
+	}
+
+	if arch != fm.SystemInfo.FrpsArch {
+		return fmt.Errorf("发布包架构为 %s，与当前系统 (%s) 不匹配", arch, fm.SystemInfo.FrpsArch)
+	}
+	if opts.Version != "" && opts.Version != version {
+		return fmt.Errorf("发布包版本为 %s，与 --version 指定的 %s 不一致", version, opts.Version)
+	}
+
+	fm.SystemInfo.FrpsVersion = version
+	fm.Colors["green"].Printf("发布包版本: %s，架构: %s\n", version, arch)
+	return nil
+}
+
+// localServerIP 离线安装时从本机网卡获取 IP，不访问网络
+func localServerIP() string {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return "127.0.0.1"
+	}
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() != nil && ipnet.IP.IsGlobalUnicast() {
+			return ipnet.IP.String()
+		}
+	}
+	return "127.0.0.1"
+}
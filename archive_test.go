@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+func TestParseArchiveName(t *testing.T) {
+	tests := []struct {
+		name    string
+		version string
+		arch    string
+		ok      bool
+	}{
+		{"frp_0.61.0_linux_amd64.tar.gz", "0.61.0", "amd64", true},
+		{"frp_0.61.0_linux_arm64.tgz", "0.61.0", "arm64", true},
+		{"frp_0.61.0_linux_mips64le", "0.61.0", "mips64le", true},
+		{"frp_0.62.0-rc.1_linux_riscv64.tar.gz", "0.62.0-rc.1", "riscv64", true},
+		{"frp_0.61.0_linux_amd64.zip", "", "", false},
+		{"frp_0.61.0_windows_amd64.tar.gz", "", "", false},
+		{"frp_0.61_linux_amd64.tar.gz", "", "", false},
+		{"frp_v0.61.0_linux_amd64.tar.gz", "", "", false},
+		{"./frp_0.61.0_linux_amd64.tar.gz", "", "", false},
+		{"frp_0.61.0_linux_amd64.tar.gz.part", "", "", false},
+		{"", "", "", false},
+	}
+	for _, tt := range tests {
+		version, arch, ok := parseArchiveName(tt.name)
+		if version != tt.version || arch != tt.arch || ok != tt.ok {
+			t.Errorf("parseArchiveName(%q) = %q, %q, %v; want %q, %q, %v", tt.name, version, arch, ok, tt.version, tt.arch, tt.ok)
+		}
+	}
+}
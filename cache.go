@@ -0,0 +1,316 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultCacheDir 发布包缓存目录，按 <版本>/<架构>/<sha256>/<文件名> 存放
+const DefaultCacheDir = "/var/cache/frps-onekey"
+
+// cacheDownloadsDir 缓存目录中存放未完成下载的子目录
+const cacheDownloadsDir = "downloads"
+
+// CacheOptions cache 子命令的选项
+type CacheOptions struct {
+	Dir     string
+	MaxAge  time.Duration // 删除超过该时间未使用的缓存，0 表示不限制
+	MaxSize int64         // 缓存总大小上限，超出时从最久未使用的开始删除，0 表示不限制
+	All     bool          // 删除全部缓存
+}
+
+// cacheEntry 一个缓存的发布包
+type cacheEntry struct {
+	Version string
+	Arch    string
+	SHA256  string
+	Path    string
+	Size    int64
+	ModTime time.Time // 最近一次使用的时间
+}
+
+// cachePath 返回缓存中发布包的路径
+func cachePath(dir, version, arch, sha, filename string) string {
+	return filepath.Join(dir, version, arch, strings.ToLower(sha), filename)
+}
+
+// prepareCacheDir 返回可写的缓存目录，禁用缓存或目录无法创建时返回空，此时下载到当前目录
+func (fm *FrpsManager) prepareCacheDir(opts *DownloadOptions) string {
+	if opts.NoCache {
+		return ""
+	}
+	dir := opts.CacheDir
+	if dir == "" {
+		dir = DefaultCacheDir
+	}
+	if err := os.MkdirAll(filepath.Join(dir, cacheDownloadsDir), 0755); err != nil {
+		fm.Colors["yellow"].Printf("无法使用缓存目录 %s: %v\n", dir, err)
+		return ""
+	}
+	return dir
+}
+
+// lookupCache 查找已缓存且校验通过的发布包，文件损坏时删除该缓存
+func (fm *FrpsManager) lookupCache(dir, version, arch, sha, filename string) string {
+	if sha == "" {
+		return ""
+	}
+	path := cachePath(dir, version, arch, sha, filename)
+	if !fileExists(path) {
+		return ""
+	}
+
+	actual, err := fileSHA256(path)
+	if err != nil || !strings.EqualFold(actual, sha) {
+		fm.Colors["yellow"].Printf("缓存的 %s 校验失败，重新下载\n", filename)
+		os.RemoveAll(filepath.Dir(path))
+		return ""
+	}
+
+	// 以修改时间记录最近使用时间，供 cache prune 按时间清理
+	now := time.Now()
+	os.Chtimes(path, now, now)
+	return path
+}
+
+// findCachedArchive 在获取校验文件之前按版本与架构查找缓存的发布包，使用最近使用的一个。
+// 缓存目录名是放入缓存时校验过的 SHA-256，文件与之一致即可使用；指定了 sha 时只查找该校验值
+func (fm *FrpsManager) findCachedArchive(dir, version, arch, sha, filename string) (path, checksum string) {
+	if sha != "" {
+		return fm.lookupCache(dir, version, arch, sha, filename), strings.ToLower(sha)
+	}
+	matches, _ := filepath.Glob(filepath.Join(dir, version, arch, "*", filename))
+	sort.Slice(matches, func(i, j int) bool {
+		return modTime(matches[i]).After(modTime(matches[j]))
+	})
+	for _, match := range matches {
+		sha := filepath.Base(filepath.Dir(match))
+		if !isSHA256(sha) {
+			continue
+		}
+		if path := fm.lookupCache(dir, version, arch, sha, filename); path != "" {
+			return path, sha
+		}
+	}
+	return "", ""
+}
+
+// modTime 返回文件的修改时间，读取失败时为零值
+func modTime(path string) time.Time {
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// storeCache 将校验过的发布包放入缓存，move 为 true 时移动文件，否则复制
+func (fm *FrpsManager) storeCache(dir, version, arch, sha, src string, move bool) (string, error) {
+	dst := cachePath(dir, version, arch, sha, filepath.Base(src))
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return "", err
+	}
+	if move {
+		if err := os.Rename(src, dst); err == nil {
+			return dst, nil
+		}
+	}
+	if err := fm.copyFile(src, dst, 0644); err != nil {
+		os.Remove(dst)
+		return "", err
+	}
+	if move {
+		os.Remove(src)
+	}
+	return dst, nil
+}
+
+// listCache 列出缓存中的发布包，按最近使用时间倒序排列
+func listCache(dir string) ([]cacheEntry, error) {
+	var entries []cacheEntry
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) && path == dir {
+				return filepath.SkipDir
+			}
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		parts := strings.Split(rel, string(filepath.Separator))
+		if d.IsDir() {
+			if parts[0] == cacheDownloadsDir {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if len(parts) != 4 || !isSHA256(parts[2]) {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		entries = append(entries, cacheEntry{
+			Version: parts[0],
+			Arch:    parts[1],
+			SHA256:  parts[2],
+			Path:    path,
+			Size:    info.Size(),
+			ModTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ModTime.After(entries[j].ModTime) })
+	return entries, nil
+}
+
+// removeCacheEntry 删除缓存的发布包及其空目录
+func removeCacheEntry(dir string, entry cacheEntry) error {
+	if err := os.RemoveAll(filepath.Dir(entry.Path)); err != nil {
+		return err
+	}
+	// 依次删除空的架构与版本目录
+	for parent := filepath.Dir(filepath.Dir(entry.Path)); parent != dir; parent = filepath.Dir(parent) {
+		if os.Remove(parent) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// parseSize 解析 100M、1.5G、512KB 这样的大小
+func parseSize(value string) (int64, error) {
+	s := strings.ToUpper(strings.TrimSpace(value))
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "IB"), "B")
+	multiplier := int64(1)
+	if n := len(s); n > 0 {
+		switch s[n-1] {
+		case 'K':
+			multiplier = 1 << 10
+		case 'M':
+			multiplier = 1 << 20
+		case 'G':
+			multiplier = 1 << 30
+		}
+		if multiplier > 1 {
+			s = s[:n-1]
+		}
+	}
+	number, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	size := number * float64(multiplier)
+	if err != nil || math.IsNaN(size) || size < 0 || size >= math.MaxInt64 {
+		return 0, fmt.Errorf("无效的大小: %s，应为 500M、2G 这样的格式", value)
+	}
+	return int64(size), nil
+}
+
+// parseAge 解析时长，在 time.ParseDuration 的基础上支持天 (d)
+func parseAge(value string) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if strings.HasSuffix(value, "d") {
+		n, err := strconv.ParseFloat(strings.TrimSuffix(value, "d"), 64)
+		d := n * float64(24*time.Hour)
+		if err != nil || math.IsNaN(d) || d < 0 || d >= math.MaxInt64 {
+			return 0, fmt.Errorf("无效的时长: %s，应为 30d、12h 这样的格式", value)
+		}
+		return time.Duration(d), nil
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("无效的时长: %s，应为 30d、12h 这样的格式", value)
+	}
+	return d, nil
+}
+
+// CacheList 列出缓存的发布包
+func (fm *FrpsManager) CacheList(opts *CacheOptions) {
+	entries, err := listCache(opts.Dir)
+	if err != nil {
+		fm.Colors["red"].Printf("读取缓存失败: %v\n", err)
+		return
+	}
+
+	fmt.Printf("============== 发布包缓存 (%s) ==============\n", opts.Dir)
+	if len(entries) == 0 {
+		fm.Colors["yellow"].Println("  缓存为空")
+		return
+	}
+
+	var total int64
+	for _, entry := range entries {
+		fmt.Printf("  %-12s %-8s %s  %10s  %s\n", entry.Version, entry.Arch, entry.SHA256[:12],
+			fm.formatBytes(entry.Size), entry.ModTime.Format("2006-01-02 15:04"))
+		total += entry.Size
+	}
+	fmt.Printf("共 %d 个发布包，%s\n", len(entries), fm.formatBytes(total))
+}
+
+// CachePrune 按时间与大小清理缓存
+func (fm *FrpsManager) CachePrune(opts *CacheOptions) {
+	if !fm.checkRoot() {
+		return
+	}
+
+	entries, err := listCache(opts.Dir)
+	if err != nil {
+		fm.Colors["red"].Printf("读取缓存失败: %v\n", err)
+		return
+	}
+
+	var total int64
+	for _, entry := range entries {
+		total += entry.Size
+	}
+
+	// entries 按最近使用时间倒序，从末尾开始删除
+	removed, freed := 0, int64(0)
+	for i := len(entries) - 1; i >= 0; i-- {
+		entry := entries[i]
+		expired := opts.MaxAge > 0 && time.Since(entry.ModTime) > opts.MaxAge
+		oversize := opts.MaxSize > 0 && total > opts.MaxSize
+		if !opts.All && !expired && !oversize {
+			continue
+		}
+		if err := removeCacheEntry(opts.Dir, entry); err != nil {
+			fm.Colors["red"].Printf("删除 %s 失败: %v\n", entry.Path, err)
+			continue
+		}
+		fmt.Printf("已删除 %s (%s, %s)\n", filepath.Base(entry.Path), entry.SHA256[:12], fm.formatBytes(entry.Size))
+		total -= entry.Size
+		freed += entry.Size
+		removed++
+	}
+
+	// 清理过期的未完成下载
+	downloads := filepath.Join(opts.Dir, cacheDownloadsDir)
+	if files, err := os.ReadDir(downloads); err == nil {
+		for _, file := range files {
+			info, err := file.Info()
+			if err != nil {
+				continue
+			}
+			if opts.All || (opts.MaxAge > 0 && time.Since(info.ModTime()) > opts.MaxAge) {
+				path := filepath.Join(downloads, file.Name())
+				if err := os.Remove(path); err != nil {
+					fm.Colors["red"].Printf("删除 %s 失败: %v\n", path, err)
+					continue
+				}
+				freed += info.Size()
+			}
+		}
+	}
+
+	fm.Colors["green"].Printf("已删除 %d 个发布包，释放 %s，剩余 %s\n", removed, fm.formatBytes(freed), fm.formatBytes(total))
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := []struct {
+		value string
+		want  int64
+		ok    bool
+	}{
+		{"0", 0, true},
+		{"1024", 1024, true},
+		{"512K", 512 << 10, true},
+		{"512KB", 512 << 10, true},
+		{"100M", 100 << 20, true},
+		{"100mb", 100 << 20, true},
+		{"100MiB", 100 << 20, true},
+		{"1.5G", 3 << 29, true},
+		{" 2G ", 2 << 30, true},
+		{"10B", 10, true},
+		{"", 0, false},
+		{"M", 0, false},
+		{"-1M", 0, false},
+		{"10T", 0, false},
+		{"abc", 0, false},
+		{"inf", 0, false},
+		{"+InfG", 0, false},
+		{"NaN", 0, false},
+		{"1e30G", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseSize(tt.value)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseSize(%q) = %d, %v; want %d, ok=%v", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestParseAge(t *testing.T) {
+	tests := []struct {
+		value string
+		want  time.Duration
+		ok    bool
+	}{
+		{"30d", 30 * 24 * time.Hour, true},
+		{"1.5d", 36 * time.Hour, true},
+		{"0d", 0, true},
+		{"12h", 12 * time.Hour, true},
+		{"90m", 90 * time.Minute, true},
+		{" 7d ", 7 * 24 * time.Hour, true},
+		{"", 0, false},
+		{"d", 0, false},
+		{"-1d", 0, false},
+		{"-1h", 0, false},
+		{"30", 0, false},
+		{"1w", 0, false},
+		{"infd", 0, false},
+		{"NaNd", 0, false},
+		{"1e30d", 0, false},
+	}
+	for _, tt := range tests {
+		got, err := parseAge(tt.value)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("parseAge(%q) = %v, %v; want %v, ok=%v", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestFindCachedArchive(t *testing.T) {
+	dir := t.TempDir()
+	const name = "frp_0.61.0_linux_amd64.tar.gz"
+	content := []byte("release")
+	sum := sha256.Sum256(content)
+	good := hex.EncodeToString(sum[:])
+	bad := strings.Repeat("0", 64)
+	for sha, body := range map[string][]byte{good: content, bad: []byte("corrupted")} {
+		path := cachePath(dir, "0.61.0", "amd64", sha, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, body, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 损坏的文件最近使用，应被跳过并删除
+	now := time.Now()
+	os.Chtimes(cachePath(dir, "0.61.0", "amd64", bad, name), now, now)
+	os.Chtimes(cachePath(dir, "0.61.0", "amd64", good, name), now.Add(-time.Hour), now.Add(-time.Hour))
+
+	fm := NewFrpsManager()
+	path, checksum := fm.findCachedArchive(dir, "0.61.0", "amd64", "", name)
+	if path != cachePath(dir, "0.61.0", "amd64", good, name) || checksum != good {
+		t.Fatalf("findCachedArchive() = %q, %q; want the verified entry", path, checksum)
+	}
+	if fileExists(cachePath(dir, "0.61.0", "amd64", bad, name)) {
+		t.Error("corrupted cache entry was not removed")
+	}
+	if path, _ := fm.findCachedArchive(dir, "0.61.0", "amd64", bad, name); path != "" {
+		t.Errorf("findCachedArchive() with a pinned checksum = %q, want miss", path)
+	}
+	if path, _ := fm.findCachedArchive(dir, "0.62.0", "amd64", "", name); path != "" {
+		t.Errorf("findCachedArchive() for another version = %q, want miss", path)
+	}
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ChecksumFileName frp 每个版本随发布包一起发布的 SHA-256 校验文件
+const ChecksumFileName = "frp_sha256_checksums.txt"
+
+// parseChecksums 解析 sha256sum 格式的校验文件，返回 文件名 -> 校验值
+func parseChecksums(content string) map[string]string {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || !isSHA256(fields[0]) {
+			continue
+		}
+		// 二进制模式下文件名带有 '*' 前缀
+		name := strings.TrimPrefix(fields[1], "*")
+		checksums[name] = strings.ToLower(fields[0])
+	}
+	return checksums
+}
+
+// isSHA256 检查字符串是否为 64 位十六进制的 SHA-256 值
+func isSHA256(s string) bool {
+	if len(s) != sha256.Size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// fetchChecksums 从与发布包相同的下载源获取校验文件
+func fetchChecksums(ctx context.Context, url string) (map[string]string, error) {
+	resp, err := httpGet(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return nil, err
+	}
+
+	checksums := parseChecksums(string(body))
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("%s 中没有有效的校验值", url)
+	}
+	return checksums, nil
+}
+
+// readLocalChecksums 离线安装时读取与发布包位于同一目录的校验文件
+func readLocalChecksums(archive string) (map[string]string, error) {
+	content, err := os.ReadFile(filepath.Join(filepath.Dir(archive), ChecksumFileName))
+	if err != nil {
+		return nil, fmt.Errorf("离线安装需要将 %s 放在发布包所在目录: %v", ChecksumFileName, err)
+	}
+	checksums := parseChecksums(string(content))
+	if len(checksums) == 0 {
+		return nil, fmt.Errorf("%s 中没有有效的校验值", ChecksumFileName)
+	}
+	return checksums, nil
+}
+
+// fileSHA256 计算文件的 SHA-256
+func fileSHA256(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// expectedChecksum 获取发布包期望的 SHA-256，未指定校验值时从下载源获取校验文件，跳过校验时返回空
+func (fm *FrpsManager) expectedChecksum(ctx context.Context, filename string, mirror *Mirror, opts *DownloadOptions) (string, error) {
+	if opts.SkipVerify {
+		return "", nil
+	}
+	if opts.SHA256 != "" {
+		return strings.ToLower(opts.SHA256), nil
+	}
+
+	var checksums map[string]string
+	var err error
+	if opts.FromArchive != "" {
+		checksums, err = readLocalChecksums(opts.FromArchive)
+	} else {
+		fm.Colors["green"].Printf("正在获取校验文件 %s...\n", ChecksumFileName)
+		checksums, err = fetchChecksums(ctx, mirror.URL(fm.SystemInfo.FrpsVersion, fm.SystemInfo.FrpsArch, ChecksumFileName))
+	}
+	if err != nil {
+		return "", fmt.Errorf("获取校验文件失败: %v (可使用 --sha256 指定校验值，或 --skip-verify 跳过校验)", err)
+	}
+	expected := checksums[filename]
+	if expected == "" {
+		return "", fmt.Errorf("校验文件中没有 %s 的校验值", filename)
+	}
+	return expected, nil
+}
+
+// verifyArchive 校验发布包，expected 为空表示跳过校验，返回文件实际的 SHA-256
+func (fm *FrpsManager) verifyArchive(path, expected string) (string, error) {
+	actual, err := fileSHA256(path)
+	if err != nil {
+		return "", fmt.Errorf("计算 SHA-256 失败: %v", err)
+	}
+	if err := fm.checkChecksum(actual, expected); err != nil {
+		return "", err
+	}
+	return actual, nil
+}
+
+// checkChecksum 比较实际与期望的 SHA-256，expected 为空表示跳过校验
+func (fm *FrpsManager) checkChecksum(actual, expected string) error {
+	if expected == "" {
+		fm.Colors["yellow"].Println("警告：已跳过 SHA-256 校验 (--skip-verify)")
+		return nil
+	}
+	if !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("SHA-256 校验失败，文件可能已损坏或被篡改\n  期望: %s\n  实际: %s", strings.ToLower(expected), actual)
+	}
+
+	fm.Colors["green"].Printf("✓ SHA-256 校验通过: %s\n", actual)
+	return nil
+}
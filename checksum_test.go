@@ -0,0 +1,50 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseChecksums(t *testing.T) {
+	sumA := strings.Repeat("a", 64)
+	sumB := strings.Repeat("0123456789ABCDEF", 4)
+	tests := []struct {
+		name    string
+		content string
+		want    map[string]string
+	}{
+		{"empty", "", map[string]string{}},
+		{"text mode", sumA + "  frp_0.61.0_linux_amd64.tar.gz\n", map[string]string{
+			"frp_0.61.0_linux_amd64.tar.gz": sumA,
+		}},
+		{"binary mode and uppercase", sumB + " *frp_0.61.0_linux_arm64.tar.gz\n", map[string]string{
+			"frp_0.61.0_linux_arm64.tar.gz": strings.ToLower(sumB),
+		}},
+		{"windows line endings", sumA + "  a.tar.gz\r\n" + sumB + "  b.zip\r\n", map[string]string{
+			"a.tar.gz": sumA,
+			"b.zip":    strings.ToLower(sumB),
+		}},
+		{"invalid lines skipped", strings.Join([]string{
+			"# comment",
+			"",
+			strings.Repeat("a", 63) + "  short.tar.gz",
+			strings.Repeat("g", 64) + "  nothex.tar.gz",
+			sumA + "  name with spaces.tar.gz",
+			sumA,
+			sumA + "  ok.tar.gz",
+		}, "\n"), map[string]string{
+			"ok.tar.gz": sumA,
+		}},
+		{"later entry wins", sumA + "  x.tar.gz\n" + sumB + "  x.tar.gz\n", map[string]string{
+			"x.tar.gz": strings.ToLower(sumB),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := parseChecksums(tt.content); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseChecksums() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
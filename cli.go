@@ -0,0 +1,772 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// EnvPrefix 环境变量前缀，例如 --bind-port 对应 FRPS_ONEKEY_BIND_PORT
+const EnvPrefix = "FRPS_ONEKEY_"
+
+// DownloadOptions 下载相关选项，install 与 update 共用
+type DownloadOptions struct {
+	NetworkOptions
+	Source      string   // 下载源名称，为空表示未指定
+	SHA256      string   // 期望的发布包 SHA-256，为空时从下载源获取校验文件
+	SkipVerify  bool     // 跳过 SHA-256 校验
+	Version     string   // 指定安装的版本，为空时安装最新版本
+	Prerelease  bool     // 获取最新版本时包含预发布版本
+	FromArchive string   // 离线安装使用的本地发布包，设置后不访问网络
+	MirrorsFile string   // 下载源配置文件，为空时使用默认位置
+	Mirrors     []string // --mirror 指定的发布包地址模板
+	CacheDir    string   // 发布包缓存目录，为空时使用默认位置
+	NoCache     bool     // 不读取也不写入缓存
+
+	probedVersion string // 自动选择下载源时已确定的版本
+}
+
+// InstallOptions 安装选项
+type InstallOptions struct {
+	DownloadOptions
+	AnswersFile    string          // 无人值守安装使用的应答文件
+	ClientConfig   string          // 安装完成后生成 frpc 配置的输出目标，见 ClientConfigOptions.Output
+	ClientProtocol string          // 生成的 frpc 配置使用的连接协议，见 ClientConfigOptions.Protocol
+	Config         Config          // 命令行参数与环境变量提供的配置
+	Present        map[string]bool // Config 中已设置的字段
+}
+
+// ClientInstallOptions client install 子命令的选项
+type ClientInstallOptions struct {
+	DownloadOptions
+	Client        ClientConfig    // 命令行参数与环境变量提供的 frpc 配置
+	Present       map[string]bool // Client 中已设置的字段
+	AllowPorts    string          // 服务端的 allowPorts，保存后 client proxy add 默认使用
+	SubDomainHost string          // 服务端的 subDomainHost，保存后 client proxy add 默认使用
+}
+
+// ImportOptions import-config 子命令的选项
+type ImportOptions struct {
+	Path       string // 要导入的配置文件
+	SecretsEnv bool   // 将 token 与 Dashboard 密码移到环境变量文件
+}
+
+// UpdateOptions 更新选项
+type UpdateOptions struct {
+	DownloadOptions
+}
+
+// VersionsOptions versions 子命令的选项
+type VersionsOptions struct {
+	DownloadOptions
+	Page       int
+	PerPage    int
+	Prerelease bool // 同时列出预发布版本
+}
+
+// configFlagUsage 配置项对应命令行参数的说明
+var configFlagUsage = map[string]string{
+	"bind_port":          "frps 绑定端口",
+	"dashboard_port":     "Dashboard 端口",
+	"vhost_http_port":    "vhost http 端口",
+	"vhost_https_port":   "vhost https 端口",
+	"dashboard_user":     "Dashboard 用户名",
+	"dashboard_pwd":      "Dashboard 密码",
+	"token":              "认证 token",
+	"subdomain_host":     "子域名主机",
+	"max_pool_count":     "最大连接池 [1-50]",
+	"log_level":          "日志级别 (trace, debug, info, warn, error)",
+	"log_max_days":       "日志保存天数 [1-15]",
+	"log_file":           "日志文件路径，/dev/null 表示不记录",
+	"tcp_mux":            "启用 TCP 多路复用",
+	"kcp_bind_port":      "KCP 绑定端口",
+	"quic_bind_port":     "QUIC 绑定端口",
+	"transport_protocol": "启用 KCP/QUIC 传输协议",
+	"secrets_env":        "将 token 与 Dashboard 密码保存到只有 root 可读的环境变量文件，frps.toml 通过 {{ .Envs.NAME }} 引用",
+}
+
+// clientFlagUsage frpc 配置项对应命令行参数的说明
+var clientFlagUsage = map[string]string{
+	"server_addr":  "frps 服务器地址",
+	"server_port":  "frps 绑定端口",
+	"token":        "认证 token，需与 frps 一致",
+	"protocol":     "连接协议 (tcp, kcp, quic, websocket, wss)",
+	"tcp_mux":      "启用 TCP 多路复用，需与 frps 一致",
+	"log_level":    "日志级别 (trace, debug, info, warn, error)",
+	"log_max_days": "日志保存天数 [1-15]",
+}
+
+// envName 返回参数对应的环境变量名
+func envName(flagName string) string {
+	return EnvPrefix + strings.ToUpper(strings.ReplaceAll(flagName, "-", "_"))
+}
+
+// newFlagSet 创建子命令的参数解析器
+func newFlagSet(name, usage string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "使用方法: frps-onekey %s\n", usage)
+		fmt.Fprintf(fs.Output(), "参数 (也可通过 %s<参数名> 环境变量设置):\n", EnvPrefix)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags 解析命令行参数，未在命令行中指定的参数从环境变量读取
+func parseFlags(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	var err error
+	fs.VisitAll(func(f *flag.Flag) {
+		if err != nil || set[f.Name] {
+			return
+		}
+		value, ok := os.LookupEnv(envName(f.Name))
+		if !ok {
+			return
+		}
+		if e := fs.Set(f.Name, value); e != nil {
+			err = fmt.Errorf("环境变量 %s 无效: %v", envName(f.Name), e)
+		}
+	})
+	return err
+}
+
+// configFlag 将命令行参数绑定到 Config 的字段
+type configFlag struct {
+	key     string
+	field   reflect.Value
+	present map[string]bool
+}
+
+func (f *configFlag) String() string {
+	if f == nil || !f.field.IsValid() || f.field.IsZero() {
+		return ""
+	}
+	return fmt.Sprint(f.field.Interface())
+}
+
+func (f *configFlag) Set(value string) error {
+	if err := setFieldFromString(f.field, value); err != nil {
+		return err
+	}
+	f.present[f.key] = true
+	return nil
+}
+
+func (f *configFlag) IsBoolFlag() bool {
+	return f.field.Kind() == reflect.Bool
+}
+
+// setFieldFromString 按字段类型解析字符串并赋值
+func setFieldFromString(field reflect.Value, value string) error {
+	switch field.Kind() {
+	case reflect.Int:
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("应为整数: %q", value)
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("应为布尔值: %q", value)
+		}
+		field.SetBool(b)
+	default:
+		field.SetString(value)
+	}
+	return nil
+}
+
+// stringListFlag 可重复指定的参数，环境变量中以逗号分隔
+type stringListFlag struct {
+	values *[]string
+}
+
+func (f *stringListFlag) String() string {
+	if f == nil || f.values == nil {
+		return ""
+	}
+	return strings.Join(*f.values, ",")
+}
+
+func (f *stringListFlag) Set(value string) error {
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			*f.values = append(*f.values, item)
+		}
+	}
+	return nil
+}
+
+// sha256Flag 发布包校验值参数
+type sha256Flag struct {
+	value *string
+}
+
+func (f *sha256Flag) String() string {
+	if f == nil || f.value == nil {
+		return ""
+	}
+	return *f.value
+}
+
+func (f *sha256Flag) Set(value string) error {
+	value = strings.ToLower(strings.TrimSpace(value))
+	if !isSHA256(value) {
+		return fmt.Errorf("无效的 SHA-256 校验值: %s，应为 64 位十六进制字符串", value)
+	}
+	*f.value = value
+	return nil
+}
+
+// versionFlag 版本号参数
+type versionFlag struct {
+	value *string
+}
+
+func (f *versionFlag) String() string {
+	if f == nil || f.value == nil {
+		return ""
+	}
+	return *f.value
+}
+
+func (f *versionFlag) Set(value string) error {
+	version, err := normalizeVersion(value)
+	if err != nil {
+		return err
+	}
+	*f.value = version
+	return nil
+}
+
+// archiveFlag 本地发布包参数，转换为绝对路径，安装过程会切换工作目录
+type archiveFlag struct {
+	value *string
+}
+
+func (f *archiveFlag) String() string {
+	if f == nil || f.value == nil {
+		return ""
+	}
+	return *f.value
+}
+
+func (f *archiveFlag) Set(value string) error {
+	abs, err := filepath.Abs(value)
+	if err != nil {
+		return err
+	}
+	stat, err := os.Stat(abs)
+	if err != nil {
+		return fmt.Errorf("无法读取发布包: %v", err)
+	}
+	if stat.IsDir() {
+		return fmt.Errorf("%s 是目录，请指定 frp_<版本>_linux_<架构>.tar.gz 文件", value)
+	}
+	*f.value = abs
+	return nil
+}
+
+// dirFlag 目录参数，转换为绝对路径
+type dirFlag struct {
+	value *string
+}
+
+func (f *dirFlag) String() string {
+	if f == nil || f.value == nil {
+		return ""
+	}
+	return *f.value
+}
+
+func (f *dirFlag) Set(value string) error {
+	abs, err := filepath.Abs(value)
+	if err != nil {
+		return err
+	}
+	*f.value = abs
+	return nil
+}
+
+// sizeFlag 大小参数，如 500M、2G
+type sizeFlag struct {
+	value *int64
+}
+
+func (f *sizeFlag) String() string {
+	if f == nil || f.value == nil || *f.value == 0 {
+		return ""
+	}
+	return strconv.FormatInt(*f.value, 10)
+}
+
+func (f *sizeFlag) Set(value string) error {
+	size, err := parseSize(value)
+	if err != nil {
+		return err
+	}
+	*f.value = size
+	return nil
+}
+
+// ageFlag 时长参数，支持天，如 30d、12h
+type ageFlag struct {
+	value *time.Duration
+}
+
+func (f *ageFlag) String() string {
+	if f == nil || f.value == nil || *f.value == 0 {
+		return ""
+	}
+	return f.value.String()
+}
+
+func (f *ageFlag) Set(value string) error {
+	age, err := parseAge(value)
+	if err != nil {
+		return err
+	}
+	*f.value = age
+	return nil
+}
+
+// registerMirrorFlags 注册下载源相关参数
+func registerMirrorFlags(fs *flag.FlagSet, opts *DownloadOptions) {
+	fs.StringVar(&opts.Source, "download-source", "", "下载源名称 (github, gitee, auto 或下载源配置中的名称)")
+	fs.StringVar(&opts.MirrorsFile, "mirrors", "", "下载源配置文件 (默认 "+MirrorsFiles[0]+")")
+	fs.Var(&stringListFlag{&opts.Mirrors}, "mirror", "自定义发布包地址模板，支持 {version} {arch} {filename}，可重复指定")
+	registerNetworkFlags(fs, &opts.NetworkOptions)
+}
+
+// registerNetworkFlags 注册网络超时与重试参数
+func registerNetworkFlags(fs *flag.FlagSet, opts *NetworkOptions) {
+	fs.DurationVar(&opts.ConnectTimeout, "connect-timeout", DefaultConnectTimeout, "建立连接的超时时间")
+	fs.DurationVar(&opts.ReadTimeout, "read-timeout", DefaultReadTimeout, "等待响应或数据的超时时间")
+	fs.IntVar(&opts.Retries, "retries", DefaultRetries, "网络请求失败后的重试次数")
+}
+
+// validate 检查网络参数
+func (o *NetworkOptions) validate() error {
+	if o.ConnectTimeout <= 0 || o.ReadTimeout <= 0 {
+		return fmt.Errorf("超时时间必须大于 0")
+	}
+	if o.Retries < 0 {
+		return fmt.Errorf("重试次数不能为负数")
+	}
+	return nil
+}
+
+// registerDownloadFlags 注册下载相关参数
+func registerDownloadFlags(fs *flag.FlagSet, opts *DownloadOptions) {
+	registerMirrorFlags(fs, opts)
+	fs.Var(&sha256Flag{&opts.SHA256}, "sha256", "发布包的 SHA-256 校验值，指定后不再下载校验文件")
+	fs.BoolVar(&opts.SkipVerify, "skip-verify", false, "跳过发布包的 SHA-256 校验 (不推荐)")
+	fs.Var(&versionFlag{&opts.Version}, "version", "安装指定版本，如 0.61.1，不再获取最新版本")
+	fs.BoolVar(&opts.Prerelease, "prerelease", false, "获取最新版本时包含预发布版本")
+	fs.Var(&archiveFlag{&opts.FromArchive}, "from-archive", "从本地 frp 发布包离线安装，不访问网络")
+	opts.CacheDir = DefaultCacheDir
+	fs.Var(&dirFlag{&opts.CacheDir}, "cache-dir", "发布包缓存目录")
+	fs.BoolVar(&opts.NoCache, "no-cache", false, "不使用发布包缓存")
+}
+
+// registerYesFlags 注册非交互模式参数
+func registerYesFlags(fs *flag.FlagSet, yes *bool) {
+	fs.BoolVar(yes, "yes", false, "非交互模式，所有确认默认为是，不读取标准输入")
+	fs.BoolVar(yes, "y", false, "同 --yes")
+	fs.BoolVar(yes, "non-interactive", false, "同 --yes")
+}
+
+// parseInstallArgs 解析 install 子命令的参数
+func parseInstallArgs(args []string, yes *bool) (*InstallOptions, error) {
+	opts := &InstallOptions{Present: make(map[string]bool)}
+	fs := newFlagSet("install", "install [参数]")
+	fs.StringVar(&opts.AnswersFile, "answers", "", "无人值守安装使用的应答文件 (JSON/TOML/YAML)")
+	fs.StringVar(&opts.ClientConfig, "client-config", "", "安装完成后生成匹配的 frpc 配置：文件路径、- (标准输出) 或 qrcode (终端二维码)")
+	fs.StringVar(&opts.ClientProtocol, "client-protocol", "", "--client-config 生成的配置使用的连接协议 (tcp, kcp, quic)，默认 tcp，kcp/quic 需要开启 transport_protocol")
+	registerDownloadFlags(fs, &opts.DownloadOptions)
+	registerYesFlags(fs, yes)
+
+	fields := answerFields(reflect.ValueOf(&opts.Config).Elem())
+	for _, key := range configKeys() {
+		name := strings.ReplaceAll(key, "_", "-")
+		fs.Var(&configFlag{key: key, field: fields[key], present: opts.Present}, name, configFlagUsage[key])
+	}
+
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("未知参数: %s", strings.Join(fs.Args(), " "))
+	}
+	if err := opts.NetworkOptions.validate(); err != nil {
+		return nil, err
+	}
+	// 与 --from-archive 一样转换为绝对路径，安装过程会切换工作目录
+	if opts.ClientConfig != "" && opts.ClientConfig != ClientConfigStdout && opts.ClientConfig != ClientConfigQRCode {
+		abs, err := filepath.Abs(opts.ClientConfig)
+		if err != nil {
+			return nil, err
+		}
+		opts.ClientConfig = abs
+	}
+	return opts, nil
+}
+
+// parseClientInstallArgs 解析 client install 子命令的参数
+func parseClientInstallArgs(args []string, yes *bool) (*ClientInstallOptions, error) {
+	opts := &ClientInstallOptions{Present: make(map[string]bool)}
+	fs := newFlagSet("client install", "client install [参数]")
+	fs.StringVar(&opts.AllowPorts, "allow-ports", "", "服务端 allowPorts，如 2000-3000,3001，保存后 client proxy add 默认使用")
+	fs.StringVar(&opts.SubDomainHost, "subdomain-host", "", "服务端 subDomainHost，保存后 client proxy add 默认使用")
+	registerDownloadFlags(fs, &opts.DownloadOptions)
+	registerYesFlags(fs, yes)
+
+	fields := answerFields(reflect.ValueOf(&opts.Client).Elem())
+	for _, key := range structKeys(reflect.TypeOf(ClientConfig{})) {
+		name := strings.ReplaceAll(key, "_", "-")
+		fs.Var(&configFlag{key: key, field: fields[key], present: opts.Present}, name, clientFlagUsage[key])
+	}
+
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("未知参数: %s", strings.Join(fs.Args(), " "))
+	}
+	if _, err := parsePortRanges(opts.AllowPorts); err != nil {
+		return nil, err
+	}
+	if err := opts.NetworkOptions.validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// parseClientConfigArgs 解析 client-config 子命令的参数
+func parseClientConfigArgs(args []string) (*ClientConfigOptions, error) {
+	opts := &ClientConfigOptions{}
+	fs := newFlagSet("client-config", "client-config [参数]")
+	fs.StringVar(&opts.ServerAddr, "server-addr", "", "frpc 连接的服务器地址，默认使用检测到的公网 IP")
+	fs.StringVar(&opts.Protocol, "protocol", "tcp", "连接协议 (tcp, kcp, quic)，kcp/quic 需要 frps 已开启")
+	fs.StringVar(&opts.Output, "output", ClientConfigStdout, "输出目标：文件路径、- (标准输出) 或 qrcode (终端二维码)")
+	qrcode := fs.Bool("qrcode", false, "同 --output qrcode")
+
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("未知参数: %s", strings.Join(fs.Args(), " "))
+	}
+	if *qrcode {
+		opts.Output = ClientConfigQRCode
+	}
+	if opts.Output == "" {
+		return nil, fmt.Errorf("--output 不能为空")
+	}
+	return opts, nil
+}
+
+// parseUpdateArgs 解析 update 子命令的参数，name 为子命令名 (update 或 client update)
+func parseUpdateArgs(name string, args []string, yes *bool) (*UpdateOptions, error) {
+	opts := &UpdateOptions{}
+	fs := newFlagSet(name, name+" [参数]")
+	registerDownloadFlags(fs, &opts.DownloadOptions)
+	registerYesFlags(fs, yes)
+
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("未知参数: %s", strings.Join(fs.Args(), " "))
+	}
+	if err := opts.NetworkOptions.validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// parseImportArgs 解析 import-config 子命令的参数，配置文件路径前后都可以跟参数
+func parseImportArgs(args []string, yes *bool) (*ImportOptions, error) {
+	opts := &ImportOptions{}
+	fs := newFlagSet("import-config", "import-config [--yes] [--secrets-env] <配置文件路径>")
+	fs.BoolVar(&opts.SecretsEnv, "secrets-env", false, configFlagUsage["secrets_env"])
+	registerYesFlags(fs, yes)
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		opts.Path = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return nil, err
+		}
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("未知参数: %s", strings.Join(fs.Args(), " "))
+	}
+	return opts, nil
+}
+
+// parseSecretsArgs 解析 secrets 子命令的参数，返回操作、配置项与值
+func parseSecretsArgs(args []string, yes *bool) (action, name, value string, err error) {
+	usage := "secrets {show | set <配置项> [<值>]} [--yes]"
+	if len(args) == 0 || (args[0] != "show" && args[0] != "set") {
+		fmt.Println("使用方法: frps-onekey " + usage)
+		fmt.Println("  show  - 显示环境变量文件中的 token 与 Dashboard 密码")
+		fmt.Println("  set   - 设置 auth.token 或 webServer.password，未指定值时随机生成，配置文件改为引用环境变量")
+		return "", "", "", flag.ErrHelp
+	}
+	action = args[0]
+
+	fs := newFlagSet("secrets "+action, usage)
+	if action == "set" {
+		registerYesFlags(fs, yes)
+	}
+	if err := parseFlags(fs, args[1:]); err != nil {
+		return "", "", "", err
+	}
+	rest := fs.Args()
+	if action == "set" {
+		if len(rest) == 0 {
+			return "", "", "", fmt.Errorf("请指定配置项 (auth.token 或 webServer.password)")
+		}
+		name, rest = rest[0], rest[1:]
+		if len(rest) > 0 && !strings.HasPrefix(rest[0], "-") {
+			value, rest = rest[0], rest[1:]
+		}
+		if err := fs.Parse(rest); err != nil {
+			return "", "", "", err
+		}
+		rest = fs.Args()
+	}
+	if len(rest) > 0 {
+		return "", "", "", fmt.Errorf("未知参数: %s", strings.Join(rest, " "))
+	}
+	return action, name, value, nil
+}
+
+// parseYesArgs 解析只支持 --yes 的子命令参数，返回剩余的位置参数
+func parseYesArgs(name, usage string, args []string, yes *bool) ([]string, error) {
+	fs := newFlagSet(name, usage)
+	registerYesFlags(fs, yes)
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	return fs.Args(), nil
+}
+
+// configKeys 按 Config 的字段顺序返回所有配置项名称
+func configKeys() []string {
+	return structKeys(reflect.TypeOf(Config{}))
+}
+
+// structKeys 按字段顺序返回配置结构体的所有配置项名称
+func structKeys(t reflect.Type) []string {
+	keys := make([]string, 0, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		key := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if key != "" && key != "-" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// mergeConfig 将 src 中已设置的字段覆盖到 dst
+func mergeConfig(dst *Config, dstPresent map[string]bool, src *Config, srcPresent map[string]bool) {
+	dstFields := answerFields(reflect.ValueOf(dst).Elem())
+	srcFields := answerFields(reflect.ValueOf(src).Elem())
+	for key := range srcPresent {
+		dstFields[key].Set(srcFields[key])
+		dstPresent[key] = true
+	}
+}
+
+// parseVersionsArgs 解析 versions 子命令的参数
+func parseVersionsArgs(args []string) (*VersionsOptions, error) {
+	opts := &VersionsOptions{}
+	fs := newFlagSet("versions", "versions [参数]")
+	registerMirrorFlags(fs, &opts.DownloadOptions)
+	fs.IntVar(&opts.Page, "page", 1, "页码")
+	fs.IntVar(&opts.PerPage, "per-page", DefaultReleasesPerPage, fmt.Sprintf("每页数量 (1-%d)", MaxReleasesPerPage))
+	fs.BoolVar(&opts.Prerelease, "prerelease", false, "同时列出预发布版本")
+
+	if err := parseFlags(fs, args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("未知参数: %s", strings.Join(fs.Args(), " "))
+	}
+	if opts.Page < 1 {
+		return nil, fmt.Errorf("页码必须大于 0")
+	}
+	if opts.PerPage < 1 || opts.PerPage > MaxReleasesPerPage {
+		return nil, fmt.Errorf("每页数量必须在 1-%d 之间", MaxReleasesPerPage)
+	}
+	if err := opts.NetworkOptions.validate(); err != nil {
+		return nil, err
+	}
+	return opts, nil
+}
+
+// parseCacheArgs 解析 cache 子命令的参数，返回 list 或 prune
+func parseCacheArgs(args []string) (string, *CacheOptions, error) {
+	usage := "cache {list|prune} [参数]"
+	if len(args) == 0 || (args[0] != "list" && args[0] != "prune") {
+		fmt.Println("使用方法: frps-onekey " + usage)
+		fmt.Println("  list   - 列出缓存的发布包")
+		fmt.Println("  prune  - 清理缓存 (--max-size、--max-age 或 --all)")
+		return "", nil, flag.ErrHelp
+	}
+	action := args[0]
+
+	opts := &CacheOptions{Dir: DefaultCacheDir}
+	fs := newFlagSet("cache "+action, usage)
+	fs.Var(&dirFlag{&opts.Dir}, "cache-dir", "发布包缓存目录")
+	if action == "prune" {
+		fs.Var(&sizeFlag{&opts.MaxSize}, "max-size", "缓存总大小上限，如 200M，超出时删除最久未使用的发布包")
+		fs.Var(&ageFlag{&opts.MaxAge}, "max-age", "删除超过该时间未使用的发布包，如 30d、12h")
+		fs.BoolVar(&opts.All, "all", false, "删除全部缓存")
+	}
+
+	if err := parseFlags(fs, args[1:]); err != nil {
+		return "", nil, err
+	}
+	if fs.NArg() > 0 {
+		return "", nil, fmt.Errorf("未知参数: %s", strings.Join(fs.Args(), " "))
+	}
+	if action == "prune" && !opts.All && opts.MaxSize == 0 && opts.MaxAge == 0 {
+		return "", nil, fmt.Errorf("请指定 --max-size、--max-age 或 --all")
+	}
+	return action, opts, nil
+}
+
+// parseConfigArgs 解析 config 与 client config 子命令的参数，没有参数时为 edit，返回操作与位置参数。
+// client config 不支持 get/set/unset
+func parseConfigArgs(name string, args []string, yes *bool) (string, []string, error) {
+	usage := name + " [history | diff <版本> [<版本>|current] | rollback <版本>] [--yes]"
+	// 每个操作的位置参数个数范围
+	counts := map[string][2]int{"edit": {0, 0}, "history": {0, 0}, "diff": {1, 2}, "rollback": {1, 1}}
+	if name == "config" {
+		usage = "config [get <配置项> | set <配置项> <值> | unset <配置项> | history | diff <版本> [<版本>|current] | rollback <版本>] [--yes]"
+		counts["get"], counts["set"], counts["unset"] = [2]int{1, 1}, [2]int{2, 2}, [2]int{1, 1}
+	}
+	// 没有指定操作 (只有参数) 时编辑配置文件
+	action := "edit"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+	count, ok := counts[action]
+	if !ok {
+		fmt.Println("使用方法: frps-onekey " + usage)
+		fmt.Println("  (无参数)  - 使用编辑器编辑配置文件")
+		if name == "config" {
+			fmt.Println("  get       - 查看配置项的值，如 config get webServer.port")
+			fmt.Println("  set       - 设置配置项，如 config set log.level debug")
+			fmt.Println("  unset     - 删除配置项，恢复为 frps 的默认值")
+		}
+		fmt.Println("  history   - 列出配置文件的历史版本")
+		fmt.Println("  diff      - 比较两个历史版本，只指定一个版本时与当前配置比较")
+		fmt.Println("  rollback  - 校验并回滚到指定的历史版本，然后重启服务")
+		return "", nil, flag.ErrHelp
+	}
+
+	fs := newFlagSet(strings.TrimSuffix(name+" "+action, " edit"), usage)
+	if action == "edit" || action == "set" || action == "unset" || action == "rollback" {
+		registerYesFlags(fs, yes)
+	}
+	if err := parseFlags(fs, args); err != nil {
+		return "", nil, err
+	}
+	// 位置参数之后仍可以跟 --yes，值可能以 - 开头 (如负数)，所以先取出位置参数再解析剩余的参数
+	rest := fs.Args()
+	if len(rest) < count[0] {
+		return "", nil, fmt.Errorf("参数不足，使用方法: frps-onekey %s", usage)
+	}
+	n := len(rest)
+	if n > count[1] {
+		n = count[1]
+	}
+	// 可选的位置参数不能是参数名
+	for n > count[0] && strings.HasPrefix(rest[n-1], "-") {
+		n--
+	}
+	positional := rest[:n]
+	if err := fs.Parse(rest[n:]); err != nil {
+		return "", nil, err
+	}
+	if fs.NArg() > 0 {
+		return "", nil, fmt.Errorf("未知参数: %s", strings.Join(fs.Args(), " "))
+	}
+	if action == "diff" && len(positional) == 1 {
+		positional = append(positional, "current")
+	}
+	return action, positional, nil
+}
+
+// parseProxyArgs 解析 client proxy 子命令的参数，remove 的代理名称放在 Proxy.Name 中
+func parseProxyArgs(args []string, yes *bool) (string, *ProxyOptions, error) {
+	usage := "client proxy {add|remove|list} [参数]"
+	if len(args) == 0 || (args[0] != "add" && args[0] != "remove" && args[0] != "list") {
+		fmt.Println("使用方法: frps-onekey " + usage)
+		fmt.Println("  add     - 添加代理 (--name、--type、--local-port，tcp/udp 需要 --remote-port)")
+		fmt.Println("  remove  - 删除代理 (remove <名称>)")
+		fmt.Println("  list    - 列出已配置的代理")
+		return "", nil, flag.ErrHelp
+	}
+	action := args[0]
+
+	opts := &ProxyOptions{}
+	p := &opts.Proxy
+	fs := newFlagSet("client proxy "+action, usage)
+	switch action {
+	case "add":
+		fs.StringVar(&p.Name, "name", "", "代理名称，不能与已有代理重复")
+		fs.StringVar(&p.Type, "type", "tcp", "代理类型 ("+strings.Join(proxyTypes, ", ")+")")
+		fs.StringVar(&p.LocalIP, "local-ip", "127.0.0.1", "本地服务地址")
+		fs.IntVar(&p.LocalPort, "local-port", 0, "本地服务端口")
+		fs.IntVar(&p.RemotePort, "remote-port", 0, "服务端监听端口 (tcp/udp)")
+		fs.Var(&stringListFlag{&p.CustomDomains}, "custom-domains", "自定义域名 (http/https)，可重复指定或以逗号分隔")
+		fs.StringVar(&p.SubDomain, "subdomain", "", "子域名 (http/https)，需要服务端设置 subDomainHost")
+		fs.StringVar(&p.SecretKey, "secret-key", "", "stcp 密钥，未指定时随机生成")
+		fs.StringVar(&opts.AllowPorts, "allow-ports", "", "服务端 allowPorts，如 2000-3000,3001，默认使用 client install 保存的值或本机 frps.toml")
+		fs.StringVar(&opts.SubDomainHost, "subdomain-host", "", "服务端 subDomainHost，默认使用 client install 保存的值或本机 frps.toml")
+		registerYesFlags(fs, yes)
+	case "remove":
+		registerYesFlags(fs, yes)
+	}
+
+	if err := parseFlags(fs, args[1:]); err != nil {
+		return "", nil, err
+	}
+	rest := fs.Args()
+	if action == "remove" && len(rest) > 0 {
+		// 名称之后仍可以跟 --yes
+		p.Name = rest[0]
+		if err := fs.Parse(rest[1:]); err != nil {
+			return "", nil, err
+		}
+		rest = fs.Args()
+	}
+	if len(rest) > 0 {
+		return "", nil, fmt.Errorf("未知参数: %s", strings.Join(rest, " "))
+	}
+	if action != "list" && p.Name == "" {
+		return "", nil, fmt.Errorf("请指定代理名称")
+	}
+	return action, opts, nil
+}
@@ -0,0 +1,367 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// 客户端配置的默认值
+const (
+	DefaultClientProtocol = "tcp"
+)
+
+// clientProtocols frpc 连接 frps 支持的协议
+var clientProtocols = []string{"tcp", "kcp", "quic", "websocket", "wss"}
+
+// ClientConfig frpc 连接 frps 的配置
+type ClientConfig struct {
+	ServerAddr string `json:"server_addr"`
+	ServerPort int    `json:"server_port"`
+	Token      string `json:"token"`
+	Protocol   string `json:"protocol"`
+	TCPMux     bool   `json:"tcp_mux"`
+	LogLevel   string `json:"log_level"`
+	LogMaxDays int    `json:"log_max_days"`
+}
+
+// render 生成 frpc.toml，logFile 为空时输出到控制台
+func (c *ClientConfig) render(logFile string) string {
+	if logFile == "" {
+		logFile = "console"
+	}
+	return fmt.Sprintf(`serverAddr = "%s"
+serverPort = %d
+
+# auth.method specifies what authentication method to use to authenticate frpc with frps.
+auth.method = "token"
+auth.token = "%s"
+
+# Communication protocol used to connect to server
+# supports tcp, kcp, quic, websocket and wss now, default is tcp
+transport.protocol = "%s"
+
+# If tcp stream multiplexing is used, must be same with frps
+transport.tcpMux = %t
+
+# console or real logFile path like ./frpc.log
+log.to = "%s"
+# trace, debug, info, warn, error
+log.level = "%s"
+log.maxDays = %d
+
+# Add proxies with 'frps-onekey client proxy add', or by hand like:
+# [[proxies]]
+# name = "ssh"
+# type = "tcp"
+# localIP = "127.0.0.1"
+# localPort = 22
+# remotePort = 6000
+`, c.ServerAddr, c.ServerPort, c.Token, c.Protocol, c.TCPMux, logFile, c.LogLevel, c.LogMaxDays)
+}
+
+// validate 校验客户端配置
+func (c *ClientConfig) validate() FieldErrors {
+	var errs FieldErrors
+	for _, s := range []struct {
+		key   string
+		value string
+	}{
+		{"server_addr", c.ServerAddr},
+		{"token", c.Token},
+	} {
+		if strings.TrimSpace(s.value) == "" {
+			errs = append(errs, FieldError{s.key, "不能为空"})
+		} else if strings.ContainsAny(s.value, "\"\\\r\n") {
+			errs = append(errs, FieldError{s.key, "不能包含引号、反斜杠或换行符"})
+		}
+	}
+	if c.ServerPort < 1 || c.ServerPort > 65535 {
+		errs = append(errs, FieldError{"server_port", fmt.Sprintf("端口 %d 超出范围 [1-65535]", c.ServerPort)})
+	}
+	if !containsString(clientProtocols, c.Protocol) {
+		errs = append(errs, FieldError{"protocol", fmt.Sprintf("无效的协议 %q (可选 %s)", c.Protocol, strings.Join(clientProtocols, ", "))})
+	}
+	if c.LogMaxDays < 1 || c.LogMaxDays > 15 {
+		errs = append(errs, FieldError{"log_max_days", fmt.Sprintf("%d 超出范围 [1-15]", c.LogMaxDays)})
+	}
+	switch c.LogLevel {
+	case "trace", "debug", "info", "warn", "error":
+	default:
+		errs = append(errs, FieldError{"log_level", fmt.Sprintf("无效的日志级别 %q (可选 trace, debug, info, warn, error)", c.LogLevel)})
+	}
+	return errs
+}
+
+// containsString 检查列表中是否包含 s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// applyClientDefaults 为未提供的字段填充默认值，服务器地址与 token 没有默认值
+func applyClientDefaults(c *ClientConfig, present map[string]bool) {
+	if !present["server_port"] {
+		c.ServerPort = DefaultBindPort
+	}
+	if !present["protocol"] {
+		c.Protocol = DefaultClientProtocol
+	}
+	if !present["tcp_mux"] {
+		c.TCPMux = true
+	}
+	if !present["log_level"] {
+		c.LogLevel = DefaultLogLevel
+	}
+	if !present["log_max_days"] {
+		c.LogMaxDays = DefaultLogMaxDays
+	}
+}
+
+// collectClientConfig 交互式收集客户端配置，present 中的字段不再询问
+func (fm *FrpsManager) collectClientConfig(c *ClientConfig, present map[string]bool) {
+	fmt.Println()
+	fm.Colors["red"].Println("————————————————————————————————————————————")
+	fm.Colors["red"].Println("     请输入 frps 服务器的连接信息:")
+	fm.Colors["red"].Println("————————————————————————————————————————————")
+
+	if !present["server_addr"] {
+		c.ServerAddr = fm.inputRequired("server_addr")
+	}
+	if !present["server_port"] {
+		c.ServerPort = fm.inputNumber("server_port", DefaultBindPort, 65535)
+	}
+	if !present["token"] {
+		c.Token = fm.inputRequired("token")
+	}
+	if !present["protocol"] {
+		for {
+			c.Protocol = strings.ToLower(fm.inputString("protocol ("+strings.Join(clientProtocols, ", ")+")", DefaultClientProtocol))
+			if containsString(clientProtocols, c.Protocol) {
+				break
+			}
+			fm.Colors["red"].Println("输入错误！请输入支持的协议。")
+		}
+	}
+	if !present["log_level"] {
+		c.LogLevel = fm.selectLogLevel()
+	}
+	if !present["log_max_days"] {
+		c.LogMaxDays = fm.inputNumber("log_max_days", DefaultLogMaxDays, 15)
+	}
+}
+
+// inputRequired 输入没有默认值的字符串
+func (fm *FrpsManager) inputRequired(name string) string {
+	for {
+		if value := fm.inputString(name, ""); value != "" {
+			return value
+		}
+		fm.Colors["red"].Printf("%s 不能为空。\n", name)
+	}
+}
+
+// writeClientConfig 写入 frpc.toml
+func (fm *FrpsManager) writeClientConfig(c *ClientConfig) error {
+	fm.recordExistingConfig()
+	if err := writeFileAtomic(fm.Component.ConfigPath(), []byte(c.render(fm.Component.LogPath())), secretFileMode); err != nil {
+		return err
+	}
+	fm.recordConfig("client install")
+	return nil
+}
+
+// ClientInstall 安装 frpc
+func (fm *FrpsManager) ClientInstall(ctx context.Context, opts *ClientInstallOptions) {
+	if !fm.checkRoot() {
+		return
+	}
+	configureNetwork(opts.NetworkOptions)
+
+	fm.showBanner()
+
+	running := fm.isInstalled()
+	if running {
+		fm.Colors["green"].Println("frpc 已经安装并正在运行。")
+		if !fm.confirm("是否要重新安装 frpc? (y/n): ") {
+			fm.Colors["yellow"].Println("跳过安装。")
+			return
+		}
+	}
+
+	// 重新部署时保留已有配置，命令行参数或环境变量提供了连接信息时重新生成
+	configPath := fm.Component.ConfigPath()
+	keepConfig := false
+	if fileExists(configPath) && len(opts.Present) == 0 {
+		keepConfig = fm.confirm(fmt.Sprintf("检测到已有配置文件 %s，是否保留? (y/n): ", configPath))
+	}
+
+	client := &opts.Client
+	if !keepConfig {
+		applyClientDefaults(client, opts.Present)
+		if !fm.NonInteractive {
+			fm.collectClientConfig(client, opts.Present)
+		}
+		if errs := client.validate(); len(errs) > 0 {
+			fm.Colors["red"].Printf("配置无效:\n%v\n", errs)
+			return
+		}
+	}
+
+	fm.Colors["green"].Println("开始安装 frpc...")
+
+	var mirror *Mirror
+	if opts.FromArchive != "" {
+		fm.Offline = true
+		if err := fm.inspectArchive(&opts.DownloadOptions); err != nil {
+			fm.Colors["red"].Printf("%v\n", err)
+			return
+		}
+	} else {
+		if err := fm.installDependencies(); err != nil {
+			fm.Colors["red"].Printf("安装依赖包失败: %v\n", err)
+			return
+		}
+		var err error
+		if mirror, err = fm.chooseDownloadSource(ctx, &opts.DownloadOptions); err != nil {
+			fm.Colors["red"].Printf("%v\n", err)
+			return
+		}
+		if err := fm.resolveVersion(ctx, mirror, &opts.DownloadOptions); err != nil {
+			fm.Colors["red"].Printf("获取%s失败: %v\n", versionTarget(&opts.DownloadOptions), err)
+			return
+		}
+	}
+
+	writeConfig := func() error {
+		if keepConfig {
+			fm.Colors["yellow"].Printf("保留已有配置文件 %s\n", configPath)
+			return nil
+		}
+		return fm.writeClientConfig(client)
+	}
+	if err := fm.performInstall(ctx, mirror, &opts.DownloadOptions, writeConfig); err != nil {
+		fm.Colors["red"].Printf("安装失败: %v\n", err)
+		return
+	}
+
+	if opts.AllowPorts != "" || opts.SubDomainHost != "" {
+		if err := saveServerLimits(opts.AllowPorts, opts.SubDomainHost); err != nil {
+			fm.Colors["yellow"].Printf("警告：保存服务端限制失败: %v\n", err)
+		} else {
+			fm.Colors["green"].Printf("服务端限制已保存到 %s\n", serverLimitsPath())
+		}
+	}
+
+	fm.Colors["green"].Println("frpc 安装完成！")
+	fm.showClientSummary(client, keepConfig)
+}
+
+// showClientSummary 显示 frpc 安装总结
+func (fm *FrpsManager) showClientSummary(c *ClientConfig, keptConfig bool) {
+	fmt.Println("================================================")
+	if !keptConfig {
+		fm.Colors["green"].Printf("服务器地址        : %s:%d\n", c.ServerAddr, c.ServerPort)
+		fm.Colors["green"].Printf("连接协议          : %s\n", c.Protocol)
+	}
+	fm.Colors["green"].Printf("配置文件          : %s\n", fm.Component.ConfigPath())
+	fmt.Println("================================================")
+	fmt.Println()
+
+	fmt.Printf("frpc 状态管理 (%s):\n", fm.Service.Name())
+	for _, example := range [][2]string{{"启动", "start"}, {"停止", "stop"}, {"重启", "restart"}, {"状态", "status"}} {
+		fmt.Printf("  %s: ", example[0])
+		fm.Colors["green"].Println(fm.Service.ControlCommand(example[1]))
+	}
+}
+
+// runClient 执行 client 子命令，复用 frps 的下载、解压与服务管理流程
+func runClient(ctx context.Context, fm *FrpsManager, args []string) {
+	if len(args) == 0 {
+		showClientUsage()
+		return
+	}
+
+	fm.Component = ClientComponent
+	switch args[0] {
+	case "install":
+		opts, err := parseClientInstallArgs(args[1:], &fm.NonInteractive)
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		fm.ClientInstall(ctx, opts)
+	case "update":
+		opts, err := parseUpdateArgs("client update", args[1:], &fm.NonInteractive)
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		fm.Update(ctx, opts)
+	case "uninstall":
+		if _, err := parseYesArgs("client uninstall", "client uninstall [--yes]", args[1:], &fm.NonInteractive); err != nil {
+			printArgsError(err)
+			return
+		}
+		fm.Uninstall()
+	case "proxy":
+		action, opts, err := parseProxyArgs(args[1:], &fm.NonInteractive)
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		switch action {
+		case "add":
+			fm.ProxyAdd(opts)
+		case "remove":
+			fm.ProxyRemove(opts.Proxy.Name)
+		default:
+			fm.ProxyList()
+		}
+	case "config":
+		action, params, err := parseConfigArgs("client config", args[1:], &fm.NonInteractive)
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		runConfigAction(fm, action, params)
+	case "start":
+		fm.Start()
+	case "stop":
+		fm.Stop()
+	case "restart":
+		fm.Restart()
+	case "status":
+		fm.Status()
+	default:
+		showClientUsage()
+	}
+}
+
+// showClientUsage 显示 client 子命令的使用说明
+func showClientUsage() {
+	fmt.Println("frpc 管理")
+	fmt.Println("使用方法: frps-onekey client {install|uninstall|update|proxy|config|start|stop|restart|status}")
+	fmt.Println()
+	fmt.Println("命令说明:")
+	fmt.Println("  install        - 安装 frpc (--server-addr、--token 等参数，--help 查看全部参数)")
+	fmt.Println("  uninstall      - 卸载 frpc")
+	fmt.Println("  update         - 更新 frpc (--version <版本号> 切换到指定版本)")
+	fmt.Println("  proxy          - 管理代理 (add|remove|list)，修改后校验配置并询问是否重启")
+	fmt.Println("  config         - 编辑 frpc 配置文件 (history|diff|rollback 管理历史版本)")
+	fmt.Println("  start          - 启动 frpc 服务")
+	fmt.Println("  stop           - 停止 frpc 服务")
+	fmt.Println("  restart        - 重启 frpc 服务")
+	fmt.Println("  status         - 查看 frpc 状态")
+	fmt.Println()
+	fmt.Println("示例:")
+	fmt.Println("  frps-onekey client install --server-addr 1.2.3.4 --server-port 5443 --token mytoken --yes")
+	fmt.Println("  frps-onekey client proxy add --name ssh --type tcp --local-port 22 --remote-port 6000")
+	fmt.Println("  frps-onekey client proxy add --name web --type http --local-port 80 --subdomain blog")
+	fmt.Println("  frps-onekey client proxy remove ssh")
+	fmt.Println("  frps-onekey client update")
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// 生成的 frpc 配置的输出目标
+const (
+	ClientConfigStdout = "-"      // 输出到标准输出
+	ClientConfigQRCode = "qrcode" // 在终端显示二维码
+)
+
+// ClientConfigOptions client-config 子命令的选项
+type ClientConfigOptions struct {
+	ServerAddr string // frpc 连接的服务器地址，为空时使用检测到的服务器 IP
+	Protocol   string // 连接协议 tcp/kcp/quic，kcp/quic 需要 frps 已开启
+	Output     string // 文件路径、- 或 qrcode
+}
+
+// serverFile frps.toml 中校验代理所需的字段
+type serverFile struct {
+	SubDomainHost string      `toml:"subDomainHost"`
+	AllowPorts    []portRange `toml:"allowPorts"`
+}
+
+// readServerFile 解析 frps.toml
+func readServerFile(path string) (*serverFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if content, err = ServerComponent.renderConfig(content); err != nil {
+		return nil, fmt.Errorf("渲染 %s 失败:\n%v", path, err)
+	}
+	var file serverFile
+	if _, err := toml.Decode(string(content), &file); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", path, err)
+	}
+	return &file, nil
+}
+
+// serverProtocols 返回 frps 当前开启的连接协议
+func serverProtocols(cfg *Config) []string {
+	protocols := []string{"tcp"}
+	if cfg.KCPBindPort > 0 {
+		protocols = append(protocols, "kcp")
+	}
+	if cfg.QuicBindPort > 0 {
+		protocols = append(protocols, "quic")
+	}
+	return protocols
+}
+
+// clientConfigFor 根据 frps 配置生成与之匹配的 frpc 配置
+func clientConfigFor(cfg *Config, serverAddr, protocol string) (*ClientConfig, error) {
+	c := &ClientConfig{
+		ServerAddr: serverAddr,
+		Token:      cfg.Token,
+		Protocol:   protocol,
+		TCPMux:     cfg.TCPMux,
+		LogLevel:   DefaultLogLevel,
+		LogMaxDays: DefaultLogMaxDays,
+	}
+
+	// kcp 与 quic 走 UDP，frpc 的 serverPort 需要指向对应的 UDP 端口
+	switch protocol {
+	case "tcp":
+		c.ServerPort = cfg.BindPort
+	case "kcp":
+		c.ServerPort = cfg.KCPBindPort
+	case "quic":
+		c.ServerPort = cfg.QuicBindPort
+	default:
+		return nil, fmt.Errorf("不支持的协议 %q (可选 tcp, kcp, quic)", protocol)
+	}
+	if c.ServerPort == 0 {
+		return nil, fmt.Errorf("frps 未开启 %s 协议 (可选 %s)", protocol, strings.Join(serverProtocols(cfg), ", "))
+	}
+
+	if errs := c.validate(); len(errs) > 0 {
+		return nil, fmt.Errorf("生成的 frpc 配置无效:\n%v", errs)
+	}
+	return c, nil
+}
+
+// compactConfig 去掉注释与空行，用于生成二维码
+func compactConfig(content string) string {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			lines = append(lines, line)
+		}
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// writeClientConfigTo 将 frpc 配置输出到文件、标准输出或终端二维码
+func (fm *FrpsManager) writeClientConfigTo(c *ClientConfig, output string) error {
+	content := c.render("")
+	switch output {
+	case "", ClientConfigStdout:
+		fmt.Print(content)
+	case ClientConfigQRCode:
+		code, err := qrcode.New(compactConfig(content), qrcode.Low)
+		if err != nil {
+			return fmt.Errorf("生成二维码失败: %v", err)
+		}
+		fmt.Print(code.ToSmallString(false))
+	default:
+		// 配置中包含 token，只允许当前用户读取
+		if err := writeFileAtomic(output, []byte(content), secretFileMode); err != nil {
+			return fmt.Errorf("写入 %s 失败: %v", output, err)
+		}
+		fm.Colors["green"].Printf("frpc 配置已保存到 %s\n", output)
+	}
+	return nil
+}
+
+// GenerateClientConfig 根据已安装的 frps 配置生成 frpc 配置
+func (fm *FrpsManager) GenerateClientConfig(ctx context.Context, opts *ClientConfigOptions) {
+	cfg, err := loadConfigFile(ServerComponent.ConfigPath())
+	if err != nil {
+		fm.Colors["red"].Printf("读取 frps 配置失败:\n%v\n", err)
+		return
+	}
+
+	// 输出到标准输出时不打印进度，便于重定向
+	serverAddr := opts.ServerAddr
+	if serverAddr == "" {
+		if serverAddr, err = publicIP(ctx); err != nil {
+			serverAddr = localServerIP()
+		}
+	}
+
+	c, err := clientConfigFor(cfg, serverAddr, opts.Protocol)
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	if err := fm.writeClientConfigTo(c, opts.Output); err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+	}
+}
+
+// offerClientConfig 安装完成后按需生成 frpc 配置，output 为空时交互式询问输出目标，
+// protocol 为空时使用 tcp，交互式安装且开启了 KCP/QUIC 时询问；交互式输入的相对路径相对于 workDir
+func (fm *FrpsManager) offerClientConfig(serverIP, output, protocol, workDir string) {
+	if output == "" && fm.NonInteractive {
+		return
+	}
+
+	if output != "" && protocol == "" {
+		protocol = "tcp"
+		if protocols := serverProtocols(fm.Config); len(protocols) > 1 {
+			fm.Colors["yellow"].Printf("frps 已开启 %s，可以使用 --client-protocol 生成对应的 frpc 配置\n", strings.Join(protocols[1:], "/"))
+		}
+	}
+	if output == "" {
+		fmt.Println()
+		fmt.Println("是否生成与本机 frps 匹配的 frpc 配置?")
+		fmt.Println("1: 不生成 (默认)")
+		fmt.Println("2: 输出到屏幕")
+		fmt.Println("3: 保存到文件")
+		fmt.Println("4: 显示二维码")
+		fmt.Print("请选择 (1-4，默认[1]): ")
+		reader := bufio.NewReader(os.Stdin)
+		choice, _ := reader.ReadString('\n')
+		switch strings.TrimSpace(choice) {
+		case "2":
+			output = ClientConfigStdout
+		case "3":
+			output = fm.inputString("保存路径", "./frpc.toml")
+			if !filepath.IsAbs(output) && workDir != "" {
+				output = filepath.Join(workDir, output)
+			}
+		case "4":
+			output = ClientConfigQRCode
+		default:
+			return
+		}
+
+		if protocols := serverProtocols(fm.Config); protocol == "" && len(protocols) > 1 {
+			for {
+				protocol = strings.ToLower(fm.inputString("protocol ("+strings.Join(protocols, ", ")+")", "tcp"))
+				if containsString(protocols, protocol) {
+					break
+				}
+				fm.Colors["red"].Println("输入错误！请输入支持的协议。")
+			}
+		} else if protocol == "" {
+			protocol = "tcp"
+		}
+	}
+
+	c, err := clientConfigFor(fm.Config, serverIP, protocol)
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	if err := fm.writeClientConfigTo(c, output); err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+	}
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+// Component 由本工具部署的 frp 程序，服务端为 frps，客户端为 frpc
+type Component struct {
+	Name        string // 程序名，同时作为服务名
+	Description string // 服务描述
+	Dir         string // 安装目录
+	ConfigFile  string // 配置文件名，位于安装目录中
+	Command     string // 管理该组件的命令前缀，用于提示信息
+}
+
+var (
+	// ServerComponent frps，默认管理的组件
+	ServerComponent = &Component{Name: "frps", Description: "frp server", Dir: "/usr/local/frps", ConfigFile: "frps.toml", Command: "frps-onekey"}
+	// ClientComponent frpc，client 子命令管理的组件
+	ClientComponent = &Component{Name: "frpc", Description: "frp client", Dir: "/usr/local/frpc", ConfigFile: "frpc.toml", Command: "frps-onekey client"}
+)
+
+// BinaryPath 返回可执行文件路径
+func (c *Component) BinaryPath() string {
+	return filepath.Join(c.Dir, c.Name)
+}
+
+// ConfigPath 返回配置文件路径
+func (c *Component) ConfigPath() string {
+	return filepath.Join(c.Dir, c.ConfigFile)
+}
+
+// LogPath 返回默认的日志文件路径
+func (c *Component) LogPath() string {
+	return filepath.Join(c.Dir, c.Name+".log")
+}
+
+// InitScript 返回 SysV/OpenRC 初始化脚本路径
+func (c *Component) InitScript() string {
+	return "/etc/init.d/" + c.Name
+}
+
+// CommandLine 返回启动程序的命令行
+func (c *Component) CommandLine() string {
+	return fmt.Sprintf("%s -c %s", c.BinaryPath(), c.ConfigPath())
+}
+
+// initScriptContent 返回内置的 SysV 初始化脚本，脚本中的程序名与路径替换为当前组件
+func (c *Component) initScriptContent() string {
+	return strings.ReplaceAll(embeddedInitScript, ServerComponent.Name, c.Name)
+}
@@ -1,16 +1,14 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"os"
-	"os/exec"
-	"path/filepath"
 	"strconv"
 )
 
 // generateConfigFile 生成 frps 配置文件
 func (fm *FrpsManager) generateConfigFile() error {
-	configPath := filepath.Join(ProgramDir, ConfigFile)
+	configPath := ServerComponent.ConfigPath()
 	
 	logFile := fm.Config.LogFile
 	if logFile == "/dev/null" {
@@ -83,8 +81,8 @@ vhostHTTPSPort = %d
 # dashboard is available only if webServerport is set.
 webServer.addr = "0.0.0.0"
 webServer.port = %d
-webServer.user = "%s"
-webServer.password = "%s"
+webServer.user = %s
+webServer.password = %s
 # webServer.tls.certFile = "server.crt"
 # webServer.tls.keyFile = "server.key"
 # dashboard assets directory(only for debug mode)
@@ -98,9 +96,9 @@ webServer.password = "%s"
 # enablePrometheus = true
 
 # console or real logFile path like ./frps.log
-log.to = "%s"
+log.to = %s
 # trace, debug, info, warn, error
-log.level = "%s"
+log.level = %s
 log.maxDays = %d
 # disable log colors when log.to is console, default is false
 # log.disablePrintColor = false
@@ -118,7 +116,7 @@ auth.method = "token"
 # auth.additionalScopes = ["HeartBeats", "NewWorkConns"]
 
 # auth token
-auth.token = "%s"
+auth.token = %s
 
 # userConnTimeout specifies the maximum time to wait for a work connection.
 # userConnTimeout = 10
@@ -128,7 +126,7 @@ auth.token = "%s"
 
 # If subDomainHost is not empty, you can set subdomain when type is http or https in frpc's configure file
 # When subdomain is test, the host used by routing is test.frps.com
-subDomainHost = "%s"
+subDomainHost = %s
 
 # custom 404 page for HTTP requests
 # custom404Page = "/path/to/404.html"
@@ -157,103 +155,73 @@ subDomainHost = "%s"
 		fm.Config.VhostHTTPPort,
 		fm.Config.VhostHTTPSPort,
 		fm.Config.DashboardPort,
-		fm.Config.DashboardUser,
-		fm.Config.DashboardPwd,
-		logFile,
-		fm.Config.LogLevel,
+		tomlString(fm.Config.DashboardUser),
+		tomlString(fm.Config.DashboardPwd),
+		tomlString(logFile),
+		tomlString(fm.Config.LogLevel),
 		fm.Config.LogMaxDays,
-		fm.Config.Token,
-		fm.Config.SubdomainHost,
+		tomlString(fm.Config.Token),
+		tomlString(fm.Config.SubdomainHost),
 	)
 
-	return os.WriteFile(configPath, []byte(configContent), 0644)
-}
-
-// downloadInitScript 下载初始化脚本
-func (fm *FrpsManager) downloadInitScript() error {
-	// 检查本地是否已有初始化脚本（并且不是空文件）
-	if stat, err := os.Stat(InitScript); err == nil && stat.Size() > 0 {
-		fm.Colors["yellow"].Println("检测到本地已有初始化脚本，跳过下载...")
-		
-		// 设置执行权限确保可运行
-		if err := os.Chmod(InitScript, 0755); err != nil {
-			fm.Colors["yellow"].Printf("设置脚本权限失败: %v\n", err)
+	// 写回 Config 未建模的配置项，模板中已有的键原地替换
+	if len(fm.Config.Extra) > 0 {
+		doc := parseTOMLDoc([]byte(configContent))
+		for _, key := range fm.Config.extraKeys() {
+			doc.set(key, tomlValue(fm.Config.Extra[key]))
 		}
-		return nil
+		configContent = string(doc.Bytes())
 	}
 
-	fm.Colors["green"].Println("正在下载初始化脚本...")
-	
-	// 使用带进度条的下载功能（注意：由于在config.go，需要将downloadWithProgress移到这里或者共享）
-	// 首先创建临时文件，然后移动到目标位置
-	tmpScript := InitScript + ".tmp"
-	
-	if err := fm.downloadWithProgressForScript(InitScriptURL, tmpScript, "下载初始化脚本"); err != nil {
-		return fmt.Errorf("下载初始化脚本失败: %v", err)
-	}
-	
-	// 移动临时文件到目标位置
-	if err := os.Rename(tmpScript, InitScript); err != nil {
-		os.Remove(tmpScript) // 清理临时文件
-		return fmt.Errorf("移动脚本文件失败: %v", err)
+	content := []byte(configContent)
+	if fm.Config.SecretsEnv {
+		var err error
+		if content, err = fm.moveSecretsToEnv(content); err != nil {
+			return err
+		}
 	}
 
-	// 设置执行权限
-	if err := os.Chmod(InitScript, 0755); err != nil {
-		return fmt.Errorf("设置脚本权限失败: %v", err)
+	fm.recordExistingConfig()
+	if err := writeFileAtomic(configPath, content, secretFileMode); err != nil {
+		return err
 	}
-
+	fm.recordConfig("install")
 	return nil
 }
 
-
-
-// setupService 设置服务开机启动
-func (fm *FrpsManager) setupService() error {
-	fm.Colors["green"].Println("正在设置服务开机启动...")
+// setupService 通过检测到的服务管理器安装当前组件的服务并设置开机启动
+func (fm *FrpsManager) setupService(ctx context.Context) error {
+	fm.Colors["green"].Printf("正在设置服务开机启动 (%s)...\n", fm.Service.Name())
 	
-	var cmd *exec.Cmd
-	switch fm.SystemInfo.OS {
-	case "CentOS", "RHEL", "Rocky", "AlmaLinux":
-		cmd = exec.Command("chkconfig", "--add", ProgramName)
-	case "Ubuntu", "Debian":
-		cmd = exec.Command("update-rc.d", "-f", ProgramName, "defaults")
-	default:
-		return fmt.Errorf("不支持的操作系统: %s", fm.SystemInfo.OS)
+	if err := fm.Service.Install(ctx); err != nil {
+		return fmt.Errorf("安装服务失败: %v", err)
 	}
-
-	if err := cmd.Run(); err != nil {
+	if err := fm.Service.Enable(); err != nil {
 		return fmt.Errorf("设置开机启动失败: %v", err)
 	}
 
-	// 创建软链接
-	linkPath := "/usr/bin/" + ProgramName
-	if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("删除旧的软链接失败: %v", err)
-	}
-	
-	if err := os.Symlink(InitScript, linkPath); err != nil {
-		return fmt.Errorf("创建软链接失败: %v", err)
-	}
-
 	return nil
 }
 
 // startService 启动服务
 func (fm *FrpsManager) startService() error {
-	fm.Colors["green"].Println("正在启动 frps 服务...")
+	fm.Colors["green"].Printf("正在启动 %s 服务...\n", fm.Component.Name)
 	
-	cmd := exec.Command(InitScript, "start")
-	if err := cmd.Run(); err != nil {
+	// 重新安装时服务仍在运行，重启以加载新的二进制文件与配置
+	start := fm.Service.Start
+	if fm.isInstalled() {
+		start = fm.Service.Restart
+	}
+	if err := start(); err != nil {
 		return fmt.Errorf("启动服务失败: %v", err)
 	}
 
 	// 检查服务是否启动成功
 	if fm.isInstalled() {
-		fm.Colors["green"].Println("frps 服务启动成功。")
+		fm.Colors["green"].Printf("%s 服务启动成功。\n", fm.Component.Name)
 		return nil
 	} else {
-		return fmt.Errorf("frps 服务启动失败")
+		return fmt.Errorf("%s 服务启动失败", fm.Component.Name)
 	}
 }
 
@@ -295,22 +263,9 @@ func (fm *FrpsManager) showInstallationSummary(serverIP string) {
 	fmt.Println("================================================")
 	fmt.Println()
 	
-	fmt.Print("frps 状态管理: ")
-	fm.Colors["pink"].Print("frps")
-	fmt.Print(" {")
-	fm.Colors["green"].Print("start|stop|restart|status|config|version")
-	fmt.Println("}")
-	fmt.Println("示例:")
-	fmt.Print("  启动: ")
-	fm.Colors["pink"].Print("frps")
-	fmt.Print(" ")
-	fm.Colors["green"].Println("start")
-	fmt.Print("  停止: ")
-	fm.Colors["pink"].Print("frps")
-	fmt.Print(" ")
-	fm.Colors["green"].Println("stop")
-	fmt.Print("  重启: ")
-	fm.Colors["pink"].Print("frps")
-	fmt.Print(" ")
-	fm.Colors["green"].Println("restart")
-} 
\ No newline at end of file
+	fmt.Printf("frps 状态管理 (%s):\n", fm.Service.Name())
+	for _, example := range [][2]string{{"启动", "start"}, {"停止", "stop"}, {"重启", "restart"}, {"状态", "status"}} {
+		fmt.Printf("  %s: ", example[0])
+		fm.Colors["green"].Println(fm.Service.ControlCommand(example[1]))
+	}
+}
@@ -0,0 +1,337 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// configTOMLKeys Config 字段 (json 标签) 与 frps.toml 中点分隔键名的对应关系，顺序与生成的配置文件一致
+var configTOMLKeys = []struct {
+	field string
+	key   string
+}{
+	{"bind_port", "bindPort"},
+	{"kcp_bind_port", "kcpBindPort"},
+	{"quic_bind_port", "quicBindPort"},
+	{"max_pool_count", "transport.maxPoolCount"},
+	{"tcp_mux", "transport.tcpMux"},
+	{"vhost_http_port", "vhostHTTPPort"},
+	{"vhost_https_port", "vhostHTTPSPort"},
+	{"dashboard_port", "webServer.port"},
+	{"dashboard_user", "webServer.user"},
+	{"dashboard_pwd", "webServer.password"},
+	{"log_file", "log.to"},
+	{"log_level", "log.level"},
+	{"log_max_days", "log.maxDays"},
+	{"token", "auth.token"},
+	{"subdomain_host", "subDomainHost"},
+}
+
+// templateKeys generateConfigFile 模板中固定写出、不对应 Config 字段的键，显示摘要时不列出
+var templateKeys = map[string]bool{
+	"bindAddr":                   true,
+	"transport.heartbeatTimeout": true,
+	"webServer.addr":             true,
+	"auth.method":                true,
+}
+
+// bareKeyRe 可以不加引号的 TOML 键
+var bareKeyRe = regexp.MustCompile(`^[A-Za-z0-9_\-]+$`)
+
+// loadConfigFile 解析 frps.toml 为 Config，文件中未出现的字段取 frps 的默认值，
+// Config 未建模的键保存在 Extra 中
+func loadConfigFile(path string) (*Config, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	// 敏感配置项引用环境变量时保持这种写法，重新生成配置时写回环境变量文件
+	secretsEnv := usesSecretsEnv(content)
+	template := content
+	// 与 frps 一样先渲染 {{ .Envs.NAME }} 模板，得到实际生效的值
+	if content, err = ServerComponent.renderConfig(content); err != nil {
+		return nil, err
+	}
+	raw := make(map[string]interface{})
+	if _, err := toml.Decode(string(content), &raw); err != nil {
+		return nil, ConfigIssues{parseErrorIssue(string(content), err)}
+	}
+	values := make(map[string]interface{})
+	flattenTOML("", raw, values)
+
+	// frps 自身的默认值，保证未写出的字段与实际运行的配置一致
+	cfg := &Config{
+		BindPort:     7000,
+		MaxPoolCount: 5,
+		TCPMux:       true,
+		LogLevel:     "info",
+		LogMaxDays:   3,
+		LogFile:      "console",
+	}
+	fields := answerFields(reflect.ValueOf(cfg).Elem())
+	doc := parseTOMLDoc(content)
+	var issues ConfigIssues
+	for _, m := range configTOMLKeys {
+		value, ok := values[m.key]
+		if !ok {
+			continue
+		}
+		delete(values, m.key)
+		if err := setFieldFromTOML(fields[m.field], value); err != nil {
+			line, column := doc.position(m.key)
+			issues = append(issues, ConfigIssue{Line: line, Column: column, Key: m.key, Message: err.Error()})
+		}
+	}
+	if len(issues) > 0 {
+		return nil, issues
+	}
+
+	// log.to = "console" 由 log_file = /dev/null 生成
+	if cfg.LogFile == "console" {
+		cfg.LogFile = "/dev/null"
+	}
+	cfg.TransportProtocol = cfg.KCPBindPort > 0 || cfg.QuicBindPort > 0
+	cfg.SecretsEnv = secretsEnv
+	if len(values) > 0 {
+		cfg.Extra = templateValues(template, values)
+	}
+	return cfg, nil
+}
+
+// templateValues 将渲染后的值中引用了环境变量的配置项换回模板原文，写回配置文件时不泄露环境变量的值。
+// 模板都在字符串中时直接使用未渲染的内容解析出的值，否则按行替换为原文
+func templateValues(template []byte, values map[string]interface{}) map[string]interface{} {
+	if !envTemplateRe.Match(template) {
+		return values
+	}
+	raw := make(map[string]interface{})
+	if _, err := toml.Decode(string(template), &raw); err == nil {
+		unrendered := make(map[string]interface{})
+		flattenTOML("", raw, unrendered)
+		for key := range values {
+			values[key] = unrendered[key]
+		}
+		return values
+	}
+	doc := parseTOMLDoc(template)
+	for key := range values {
+		if i := doc.lookup(key); i >= 0 && envTemplateRe.MatchString(doc.valueText(i)) {
+			values[key] = tomlRaw(doc.valueText(i))
+		}
+	}
+	return values
+}
+
+// flattenTOML 将嵌套的表展开为点分隔的键，数组 (包括表数组) 作为一个值保留
+func flattenTOML(prefix string, table map[string]interface{}, out map[string]interface{}) {
+	for key, value := range table {
+		if !bareKeyRe.MatchString(key) {
+			key = tomlString(key)
+		}
+		if prefix != "" {
+			key = prefix + "." + key
+		}
+		// 子表继续展开，空表不产生任何键
+		if sub, ok := value.(map[string]interface{}); ok {
+			flattenTOML(key, sub, out)
+			continue
+		}
+		out[key] = value
+	}
+}
+
+// setFieldFromTOML 将 TOML 解析出的值写入 Config 字段
+func setFieldFromTOML(field reflect.Value, value interface{}) error {
+	switch field.Kind() {
+	case reflect.Int:
+		n, ok := value.(int64)
+		if !ok {
+			return fmt.Errorf("类型错误，应为整数")
+		}
+		field.SetInt(n)
+	case reflect.Bool:
+		b, ok := value.(bool)
+		if !ok {
+			return fmt.Errorf("类型错误，应为布尔值")
+		}
+		field.SetBool(b)
+	default:
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("类型错误，应为字符串")
+		}
+		field.SetString(s)
+	}
+	return nil
+}
+
+// tomlRaw 原样写出的 TOML 值，如包含环境变量模板的值
+type tomlRaw string
+
+// tomlValue 将值编码为单行的 TOML 值，表编码为内联表
+func tomlValue(value interface{}) string {
+	switch v := value.(type) {
+	case tomlRaw:
+		return string(v)
+	case string:
+		return tomlString(v)
+	case bool:
+		return strconv.FormatBool(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case int:
+		return strconv.Itoa(v)
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64)
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = tomlValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []map[string]interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = tomlValue(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		items := make([]string, len(keys))
+		for i, key := range keys {
+			name := key
+			if !bareKeyRe.MatchString(name) {
+				name = tomlString(name)
+			}
+			items[i] = name + " = " + tomlValue(v[key])
+		}
+		return "{ " + strings.Join(items, ", ") + " }"
+	default:
+		return tomlString(fmt.Sprint(v))
+	}
+}
+
+// tomlString 将字符串编码为 TOML 基本字符串
+func tomlString(s string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(&b, `\u%04X`, r)
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// extraKeys 返回 Extra 中的键，已排序
+func (c *Config) extraKeys() []string {
+	keys := make([]string, 0, len(c.Extra))
+	for key := range c.Extra {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// keepInstalledConfig 重新安装时保留已安装的 frps.toml 中 Config 未建模的配置项，生成配置文件时写回；
+// 已安装的配置通过环境变量文件引用敏感配置项且没有指定 secrets_env 时继续使用环境变量文件
+func (fm *FrpsManager) keepInstalledConfig(present map[string]bool) {
+	configPath := ServerComponent.ConfigPath()
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		return
+	}
+	if !present["secrets_env"] && usesSecretsEnv(content) {
+		fm.Config.SecretsEnv = true
+		fm.Colors["yellow"].Printf("已安装的配置引用了环境变量，token 与 Dashboard 密码继续保存在 %s\n", ServerComponent.EnvFile())
+	}
+
+	installed, err := loadConfigFile(configPath)
+	if err != nil {
+		fm.Colors["yellow"].Printf("读取 %s 失败，其中的其他配置项不会保留:\n%v\n", configPath, err)
+		return
+	}
+	fm.Config.Extra = installed.Extra
+	var keys []string
+	for _, key := range installed.extraKeys() {
+		if !templateKeys[key] {
+			keys = append(keys, key)
+		}
+	}
+	if len(keys) > 0 {
+		fm.Colors["yellow"].Printf("保留已安装配置中的配置项: %s\n", strings.Join(keys, ", "))
+	}
+}
+
+// loadInstalledConfig 读取已安装的 frps.toml 到 fm.Config，文件不存在时返回 false
+func (fm *FrpsManager) loadInstalledConfig() bool {
+	configPath := ServerComponent.ConfigPath()
+	if !fileExists(configPath) {
+		return false
+	}
+	cfg, err := loadConfigFile(configPath)
+	if err != nil {
+		fm.Colors["yellow"].Printf("读取 %s 失败:\n%v\n", configPath, err)
+		return false
+	}
+	fm.Config = cfg
+	return true
+}
+
+// showConfigSummary 显示当前 frps 配置的主要项
+func (fm *FrpsManager) showConfigSummary() {
+	cfg := fm.Config
+	fm.Colors["blue"].Printf("绑定端口          : %d\n", cfg.BindPort)
+	if cfg.TransportProtocol {
+		fm.Colors["blue"].Printf("KCP/QUIC 端口     : %d/%d\n", cfg.KCPBindPort, cfg.QuicBindPort)
+	}
+	fm.Colors["blue"].Printf("vhost http/https  : %d/%d\n", cfg.VhostHTTPPort, cfg.VhostHTTPSPort)
+	if cfg.DashboardPort > 0 {
+		fm.Colors["blue"].Printf("Dashboard         : 端口 %d，用户 %s\n", cfg.DashboardPort, cfg.DashboardUser)
+	}
+	if cfg.SubdomainHost != "" {
+		fm.Colors["blue"].Printf("子域名主机        : %s\n", cfg.SubdomainHost)
+	}
+	if cfg.SecretsEnv {
+		fm.Colors["blue"].Printf("敏感配置          : 环境变量文件 %s\n", ServerComponent.EnvFile())
+	}
+	var extra []string
+	for _, key := range cfg.extraKeys() {
+		if !templateKeys[key] {
+			extra = append(extra, key)
+		}
+	}
+	if len(extra) > 0 {
+		fm.Colors["blue"].Printf("其他配置项        : %s\n", strings.Join(extra, ", "))
+	}
+}
@@ -0,0 +1,404 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// valueKind 配置项的值类型
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindInt
+	kindBool
+	kindStringList
+	kindTableList
+)
+
+// keySpec 单个配置项的约束，bounded 为 true 时检查 [min, max]
+type keySpec struct {
+	kind    valueKind
+	bounded bool
+	min     int64
+	max     int64
+	enum    []string
+	fields  map[string]keySpec // kindTableList 中每一项的字段
+}
+
+var (
+	specString = keySpec{kind: kindString}
+	specInt    = keySpec{kind: kindInt}
+	specBool   = keySpec{kind: kindBool}
+	specPort   = keySpec{kind: kindInt, bounded: true, min: 1, max: 65535}
+	// specOptionalPort 为 0 时表示不启用
+	specOptionalPort = keySpec{kind: kindInt, bounded: true, min: 0, max: 65535}
+)
+
+// specEnum 取值只能是 values 之一的字符串
+func specEnum(values ...string) keySpec {
+	return keySpec{kind: kindString, enum: values}
+}
+
+// specRange 取值在 [min, max] 内的整数
+func specRange(min, max int64) keySpec {
+	return keySpec{kind: kindInt, bounded: true, min: min, max: max}
+}
+
+// frpsSchema frps.toml 支持的配置项，子表中的键以点连接
+var frpsSchema = map[string]keySpec{
+	"bindAddr":                        specString,
+	"bindPort":                        specPort,
+	"kcpBindPort":                     specOptionalPort,
+	"quicBindPort":                    specOptionalPort,
+	"proxyBindAddr":                   specString,
+	"vhostHTTPPort":                   specOptionalPort,
+	"vhostHTTPSPort":                  specOptionalPort,
+	"vhostHTTPTimeout":                specRange(0, 1<<31-1),
+	"tcpmuxHTTPConnectPort":           specOptionalPort,
+	"tcpmuxPassthrough":               specBool,
+	"subDomainHost":                   specString,
+	"custom404Page":                   specString,
+	"detailedErrorsToClient":          specBool,
+	"enablePrometheus":                specBool,
+	"userConnTimeout":                 specRange(0, 1<<31-1),
+	"maxPortsPerClient":               specRange(0, 65535),
+	"udpPacketSize":                   specRange(0, 65535),
+	"natholeAnalysisDataReserveHours": specRange(0, 1<<31-1),
+	"allowPorts": {kind: kindTableList, fields: map[string]keySpec{
+		"start":  specPort,
+		"end":    specPort,
+		"single": specPort,
+	}},
+
+	"transport.maxPoolCount":            specRange(0, 1<<31-1),
+	"transport.heartbeatTimeout":        specInt,
+	"transport.tcpMux":                  specBool,
+	"transport.tcpMuxKeepaliveInterval": specInt,
+	"transport.tcpKeepalive":            specInt,
+	"transport.quic.keepalivePeriod":    specInt,
+	"transport.quic.maxIdleTimeout":     specInt,
+	"transport.quic.maxIncomingStreams": specInt,
+	"transport.tls.force":               specBool,
+	"transport.tls.certFile":            specString,
+	"transport.tls.keyFile":             specString,
+	"transport.tls.trustedCaFile":       specString,
+
+	"webServer.addr":         specString,
+	"webServer.port":         specOptionalPort,
+	"webServer.user":         specString,
+	"webServer.password":     specString,
+	"webServer.tls.certFile": specString,
+	"webServer.tls.keyFile":  specString,
+	"webServer.assetsDir":    specString,
+	"webServer.pprofEnable":  specBool,
+
+	"log.to":                specString,
+	"log.level":             specEnum("trace", "debug", "info", "warn", "error"),
+	"log.maxDays":           specRange(0, 1<<31-1),
+	"log.disablePrintColor": specBool,
+
+	"auth.method":               specEnum("token", "oidc"),
+	"auth.additionalScopes":     {kind: kindStringList, enum: []string{"HeartBeats", "NewWorkConns"}},
+	"auth.token":                specString,
+	"auth.oidc.issuer":          specString,
+	"auth.oidc.audience":        specString,
+	"auth.oidc.skipExpiryCheck": specBool,
+	"auth.oidc.skipIssuerCheck": specBool,
+
+	"sshTunnelGateway.bindPort":              specOptionalPort,
+	"sshTunnelGateway.privateKeyFile":        specString,
+	"sshTunnelGateway.autoGenPrivateKeyPath": specString,
+	"sshTunnelGateway.authorizedKeysFile":    specString,
+
+	"httpPlugins": {kind: kindTableList, fields: map[string]keySpec{
+		"name":      specString,
+		"addr":      specString,
+		"path":      specString,
+		"ops":       {kind: kindStringList, enum: []string{"Login", "NewProxy", "CloseProxy", "Ping", "NewWorkConn", "NewUserConn"}},
+		"tlsVerify": specBool,
+	}},
+}
+
+// configSchema 返回组件配置文件的 schema，没有 schema 的组件只检查语法
+func configSchema(c *Component) map[string]keySpec {
+	if c == ServerComponent {
+		return frpsSchema
+	}
+	return nil
+}
+
+// ConfigIssue 配置文件中的一个问题，Line 为 0 表示无法定位
+type ConfigIssue struct {
+	Line    int
+	Column  int
+	Key     string
+	Message string
+}
+
+func (i ConfigIssue) Error() string {
+	location := ""
+	if i.Line > 0 {
+		location = fmt.Sprintf("第 %d 行第 %d 列 ", i.Line, i.Column)
+	}
+	if i.Key == "" {
+		return location + i.Message
+	}
+	return fmt.Sprintf("%s%s: %s", location, i.Key, i.Message)
+}
+
+// ConfigIssues 配置文件中的所有问题
+type ConfigIssues []ConfigIssue
+
+func (e ConfigIssues) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, issue := range e {
+		lines = append(lines, "  - "+issue.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// parseErrorPrefixRe ParseError.Error() 中的位置前缀
+var parseErrorPrefixRe = regexp.MustCompile(`^toml: line \d+( \(last key .*?\))?: `)
+
+// parseErrorIssue 将 TOML 语法错误转换为带行列的问题
+func parseErrorIssue(content string, err error) ConfigIssue {
+	var pe toml.ParseError
+	if !errors.As(err, &pe) {
+		return ConfigIssue{Message: err.Error()}
+	}
+
+	message := pe.Message
+	if message == "" {
+		message = parseErrorPrefixRe.ReplaceAllString(pe.Error(), "")
+	}
+	// Position.Start 为字节偏移，换算为所在行的列
+	column := pe.Position.Start + 1
+	if start := pe.Position.Start; start <= len(content) {
+		lineStart := strings.LastIndex(content[:start], "\n") + 1
+		column = len([]rune(content[lineStart:start])) + 1
+	}
+	return ConfigIssue{Line: pe.Position.Line, Column: column, Key: pe.LastKey, Message: "语法错误: " + message}
+}
+
+// validateTOML 解析 TOML 并按 schema 检查，schema 为 nil 时只检查语法
+func validateTOML(content []byte, schema map[string]keySpec) ConfigIssues {
+	raw := make(map[string]interface{})
+	if _, err := toml.Decode(string(content), &raw); err != nil {
+		return ConfigIssues{parseErrorIssue(string(content), err)}
+	}
+	if schema == nil {
+		return nil
+	}
+
+	issues := checkTable("", raw, schema)
+	doc := parseTOMLDoc(content)
+	for i := range issues {
+		issues[i].Line, issues[i].Column = doc.position(issues[i].Key)
+	}
+	sort.SliceStable(issues, func(a, b int) bool {
+		return issues[a].Line < issues[b].Line
+	})
+	return issues
+}
+
+// checkTable 检查表中的键，prefix 为表的完整键名
+func checkTable(prefix string, table map[string]interface{}, schema map[string]keySpec) ConfigIssues {
+	var issues ConfigIssues
+	keys := make([]string, 0, len(table))
+	for key := range table {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		value := table[key]
+		if spec, ok := schema[path]; ok {
+			issues = append(issues, spec.check(path, value)...)
+			continue
+		}
+		if sub, ok := value.(map[string]interface{}); ok && hasKeyPrefix(schema, path+".") {
+			issues = append(issues, checkTable(path, sub, schema)...)
+			continue
+		}
+		issues = append(issues, unknownKey(path, schema))
+	}
+	return issues
+}
+
+// hasKeyPrefix 检查 schema 中是否有以 prefix 开头的键
+func hasKeyPrefix(schema map[string]keySpec, prefix string) bool {
+	for key := range schema {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// unknownKey 生成未知配置项的问题，拼写相近时给出建议
+func unknownKey(path string, schema map[string]keySpec) ConfigIssue {
+	best, bestDistance := "", 3
+	for key := range schema {
+		if d := editDistance(strings.ToLower(path), strings.ToLower(key)); d < bestDistance || (d == bestDistance && key < best) {
+			best, bestDistance = key, d
+		}
+	}
+	if best != "" {
+		return ConfigIssue{Key: path, Message: fmt.Sprintf("未知的配置项，是否为 %s?", best)}
+	}
+	return ConfigIssue{Key: path, Message: "未知的配置项"}
+}
+
+// editDistance 计算两个字符串的编辑距离
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = minInt(minInt(prev[j]+1, cur[j-1]+1), prev[j-1]+cost)
+		}
+		prev = cur
+	}
+	return prev[len(b)]
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+// check 检查值的类型与取值范围
+func (s keySpec) check(path string, value interface{}) ConfigIssues {
+	switch s.kind {
+	case kindString:
+		str, ok := value.(string)
+		if !ok {
+			return ConfigIssues{{Key: path, Message: "类型错误，应为字符串"}}
+		}
+		if len(s.enum) > 0 && !containsString(s.enum, str) {
+			return ConfigIssues{{Key: path, Message: fmt.Sprintf("无效的值 %q (可选 %s)", str, strings.Join(s.enum, ", "))}}
+		}
+	case kindInt:
+		n, ok := value.(int64)
+		if !ok {
+			return ConfigIssues{{Key: path, Message: "类型错误，应为整数"}}
+		}
+		if s.bounded && (n < s.min || n > s.max) {
+			return ConfigIssues{{Key: path, Message: fmt.Sprintf("%d 超出范围 [%d-%d]", n, s.min, s.max)}}
+		}
+	case kindBool:
+		if _, ok := value.(bool); !ok {
+			return ConfigIssues{{Key: path, Message: "类型错误，应为布尔值"}}
+		}
+	case kindStringList:
+		items, ok := value.([]interface{})
+		if !ok {
+			return ConfigIssues{{Key: path, Message: "类型错误，应为字符串数组"}}
+		}
+		var issues ConfigIssues
+		for i, item := range items {
+			issues = append(issues, keySpec{kind: kindString, enum: s.enum}.check(fmt.Sprintf("%s[%d]", path, i), item)...)
+		}
+		return issues
+	case kindTableList:
+		return s.checkTableList(path, value)
+	}
+	return nil
+}
+
+// checkTableList 检查数组表或内联表数组中的每一项
+func (s keySpec) checkTableList(path string, value interface{}) ConfigIssues {
+	var tables []map[string]interface{}
+	switch v := value.(type) {
+	case []map[string]interface{}:
+		tables = v
+	case []interface{}:
+		for i, item := range v {
+			table, ok := item.(map[string]interface{})
+			if !ok {
+				return ConfigIssues{{Key: fmt.Sprintf("%s[%d]", path, i), Message: "类型错误，应为表"}}
+			}
+			tables = append(tables, table)
+		}
+	default:
+		return ConfigIssues{{Key: path, Message: "类型错误，应为表数组"}}
+	}
+
+	var issues ConfigIssues
+	for i, table := range tables {
+		issues = append(issues, checkTable(fmt.Sprintf("%s[%d]", path, i), table, prefixSchema(fmt.Sprintf("%s[%d]", path, i), s.fields))...)
+	}
+	return issues
+}
+
+// prefixSchema 为表数组的字段加上所在项的前缀，以便复用 checkTable
+func prefixSchema(prefix string, fields map[string]keySpec) map[string]keySpec {
+	schema := make(map[string]keySpec, len(fields))
+	for key, spec := range fields {
+		schema[prefix+"."+key] = spec
+	}
+	return schema
+}
+
+// verifyConfig 检查配置文件：TOML 语法与 schema，已安装的程序支持时再执行 verify 子命令，错误类型为 ConfigIssues
+func (fm *FrpsManager) verifyConfig(path string) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return ConfigIssues{{Message: fmt.Sprintf("读取文件失败: %v", err)}}
+	}
+	if len(strings.TrimSpace(string(content))) == 0 {
+		return ConfigIssues{{Message: "配置文件为空"}}
+	}
+	// 与 frps 一样先渲染 {{ .Envs.NAME }} 模板再解析
+	env, err := fm.Component.configEnv()
+	if err != nil {
+		return ConfigIssues{{Message: fmt.Sprintf("读取环境变量文件失败: %v", err)}}
+	}
+	content, issues := renderEnvTemplates(content, env)
+	if len(issues) > 0 {
+		return issues
+	}
+	if issues := validateTOML(content, configSchema(fm.Component)); len(issues) > 0 {
+		return issues
+	}
+
+	binary := fm.Component.BinaryPath()
+	if !fileExists(binary) {
+		return nil
+	}
+	cmd := exec.Command(binary, "verify", "-c", path)
+	for name, value := range env {
+		cmd.Env = append(cmd.Env, name+"="+value)
+	}
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		// 旧版本没有 verify 子命令，跳过
+		if strings.Contains(string(output), "unknown command") {
+			return nil
+		}
+		return ConfigIssues{{Message: fmt.Sprintf("%s verify 未通过: %s", fm.Component.Name, strings.TrimSpace(string(output)))}}
+	}
+	return nil
+}
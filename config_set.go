@@ -0,0 +1,193 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// decodeTOMLValue 解析单个 TOML 格式的值
+func decodeTOMLValue(raw string) (interface{}, error) {
+	var v struct{ V interface{} }
+	if _, err := toml.Decode("V = "+raw, &v); err != nil {
+		return nil, fmt.Errorf("无法解析的值 %s", raw)
+	}
+	return v.V, nil
+}
+
+// parseConfigValue 按配置项的类型解析命令行给出的值，并检查取值范围，错误类型为 ConfigIssues。
+// 字符串可以不加引号，字符串数组可以用逗号分隔，表数组需要写成 TOML 格式，如 [{ start = 2000, end = 3000 }]
+func parseConfigValue(key, raw string, spec keySpec) (interface{}, error) {
+	var value interface{}
+	var err error
+	switch spec.kind {
+	case kindString:
+		value = raw
+		if strings.HasPrefix(raw, `"`) || strings.HasPrefix(raw, `'`) {
+			value, err = decodeTOMLValue(raw)
+		}
+	case kindInt:
+		var n int64
+		if n, err = strconv.ParseInt(raw, 10, 64); err != nil {
+			err = fmt.Errorf("类型错误，应为整数")
+		}
+		value = n
+	case kindBool:
+		var b bool
+		if b, err = strconv.ParseBool(raw); err != nil {
+			err = fmt.Errorf("类型错误，应为 true 或 false")
+		}
+		value = b
+	case kindStringList:
+		if strings.HasPrefix(strings.TrimSpace(raw), "[") {
+			value, err = decodeTOMLValue(raw)
+			break
+		}
+		items := []interface{}{}
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		value = items
+	default:
+		value, err = decodeTOMLValue(raw)
+	}
+	if err != nil {
+		return nil, ConfigIssues{{Key: key, Message: err.Error()}}
+	}
+	if issues := spec.check(key, value); len(issues) > 0 {
+		return nil, issues
+	}
+	return value, nil
+}
+
+// readConfigDoc 读取当前组件的配置文件，同时返回展开为点分隔键名、渲染了环境变量模板的所有配置项
+func (fm *FrpsManager) readConfigDoc() (*tomlDoc, map[string]interface{}, error) {
+	configPath := fm.Component.ConfigPath()
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("配置文件 %s 不存在，请先执行 '%s install'", configPath, fm.Component.Command)
+		}
+		return nil, nil, err
+	}
+	rendered, err := fm.Component.renderConfig(content)
+	if err != nil {
+		return nil, nil, fmt.Errorf("渲染 %s 失败:\n%v", configPath, err)
+	}
+	raw := make(map[string]interface{})
+	if _, err := toml.Decode(string(rendered), &raw); err != nil {
+		return nil, nil, fmt.Errorf("解析 %s 失败:\n%v", configPath, ConfigIssues{parseErrorIssue(string(rendered), err)})
+	}
+	values := make(map[string]interface{})
+	flattenTOML("", raw, values)
+	return parseTOMLDoc(content), values, nil
+}
+
+// ConfigGet 输出配置项的值，字符串不加引号以便在脚本中使用；指定的是表时输出表中的所有配置项
+func (fm *FrpsManager) ConfigGet(key string) {
+	_, values, err := fm.readConfigDoc()
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+
+	if value, ok := values[key]; ok {
+		if s, ok := value.(string); ok {
+			fmt.Println(s)
+		} else {
+			fmt.Println(tomlValue(value))
+		}
+		return
+	}
+
+	var keys []string
+	for k := range values {
+		if strings.HasPrefix(k, key+".") {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) > 0 {
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Printf("%s = %s\n", k, tomlValue(values[k]))
+		}
+		return
+	}
+
+	schema := configSchema(fm.Component)
+	if _, ok := schema[key]; ok || hasKeyPrefix(schema, key) {
+		fm.Colors["yellow"].Printf("%s 未设置，使用 %s 的默认值\n", key, fm.Component.Name)
+		return
+	}
+	fm.Colors["red"].Printf("%v\n", unknownKey(key, schema))
+}
+
+// ConfigSet 设置配置项，按 schema 检查取值，原地修改配置文件并保留注释与其他配置项
+func (fm *FrpsManager) ConfigSet(key, raw string) {
+	if !fm.checkRoot() {
+		return
+	}
+	schema := configSchema(fm.Component)
+	spec, ok := schema[key]
+	if !ok {
+		fm.Colors["red"].Printf("%v\n", unknownKey(key, schema))
+		return
+	}
+	value, err := parseConfigValue(key, raw, spec)
+	if err != nil {
+		fm.Colors["red"].Printf("配置项的值无效:\n%v\n", err)
+		return
+	}
+
+	doc, _, err := fm.readConfigDoc()
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	// 引用环境变量的配置项修改环境变量文件，避免将值写回配置文件
+	if i := doc.lookup(key); i >= 0 && envTemplateRe.MatchString(doc.valueText(i)) {
+		fm.Colors["red"].Printf("%s 引用了环境变量，请使用 '%s secrets set %s <值>' 修改\n", key, fm.Component.Command, key)
+		return
+	}
+	// 以 [[key]] 写出的表数组无法整体替换为一个值
+	for _, t := range doc.tables() {
+		if t.Array && t.Name == key {
+			fm.Colors["red"].Printf("%s 以 [[%s]] 表的形式配置，请使用 '%s config' 编辑\n", key, key, fm.Component.Command)
+			return
+		}
+	}
+
+	doc.set(key, tomlValue(value))
+	if err := fm.applyConfigChange(doc.Bytes(), "config set "+key); err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	fm.Colors["green"].Printf("已设置 %s = %s\n", key, tomlValue(value))
+}
+
+// ConfigUnset 删除配置项，恢复为 frps 的默认值；不检查 schema，以便删除拼写错误的配置项
+func (fm *FrpsManager) ConfigUnset(key string) {
+	if !fm.checkRoot() {
+		return
+	}
+	doc, _, err := fm.readConfigDoc()
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	if !doc.unset(key) {
+		fm.Colors["yellow"].Printf("%s 未设置\n", key)
+		return
+	}
+	if err := fm.applyConfigChange(doc.Bytes(), "config unset "+key); err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	fm.Colors["green"].Printf("已删除 %s\n", key)
+}
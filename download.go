@@ -0,0 +1,332 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 分段下载参数
+const (
+	downloadSegments = 4       // 服务器支持 Range 时的最大并发分段数
+	minSegmentSize   = 1 << 20 // 每个分段的最小大小
+	partSuffix       = ".part" // 未完成的下载文件
+	partStateSuffix  = ".part.json"
+)
+
+// segment 下载分段，End 包含在内
+type segment struct {
+	Start int64 `json:"start"`
+	End   int64 `json:"end"`
+	Done  int64 `json:"done"` // 已写入的字节数
+}
+
+func (s *segment) remaining() int64 {
+	return s.End - s.Start + 1 - s.Done
+}
+
+// partialDownload 未完成下载的状态，与 .part 文件一起保存以便中断后继续
+type partialDownload struct {
+	Size      int64      `json:"size"`
+	Validator string     `json:"validator"` // ETag 或 Last-Modified，用于确认远端文件没有变化
+	Segments  []*segment `json:"segments"`
+
+	mu sync.Mutex
+}
+
+// done 返回已下载的字节数
+func (p *partialDownload) done() int64 {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	var n int64
+	for _, s := range p.Segments {
+		n += s.Done
+	}
+	return n
+}
+
+// save 保存下载状态
+func (p *partialDownload) save(path string) error {
+	p.mu.Lock()
+	data, err := json.Marshal(p)
+	p.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0644)
+}
+
+// newPartialDownload 按文件大小划分下载分段
+func newPartialDownload(size int64, validator string) *partialDownload {
+	count := int(size / minSegmentSize)
+	if count > downloadSegments {
+		count = downloadSegments
+	}
+	if count < 1 {
+		count = 1
+	}
+
+	p := &partialDownload{Size: size, Validator: validator}
+	chunk := size / int64(count)
+	for i := 0; i < count; i++ {
+		s := &segment{Start: int64(i) * chunk, End: int64(i+1)*chunk - 1}
+		if i == count-1 {
+			s.End = size - 1
+		}
+		p.Segments = append(p.Segments, s)
+	}
+	return p
+}
+
+// loadPartialDownload 读取未完成下载的状态，远端文件已变化或状态无效时返回 nil
+func loadPartialDownload(filename string, size int64, validator string) *partialDownload {
+	data, err := os.ReadFile(filename + partStateSuffix)
+	if err != nil {
+		return nil
+	}
+	stat, err := os.Stat(filename + partSuffix)
+	if err != nil || stat.Size() != size {
+		return nil
+	}
+
+	var p partialDownload
+	if err := json.Unmarshal(data, &p); err != nil || p.Size != size || p.Validator != validator || validator == "" {
+		return nil
+	}
+	for _, s := range p.Segments {
+		if s.Start < 0 || s.End >= size || s.Done < 0 || s.remaining() < 0 {
+			return nil
+		}
+	}
+	return &p
+}
+
+// removePartialDownload 删除未完成的下载文件与状态
+func removePartialDownload(filename string) {
+	os.Remove(filename + partSuffix)
+	os.Remove(filename + partStateSuffix)
+}
+
+// remoteFile 远端文件信息
+type remoteFile struct {
+	Size         int64
+	AcceptRanges bool
+	Validator    string
+}
+
+// statRemoteFile 请求第一个字节以获取文件大小并确认服务器是否支持 Range
+func statRemoteFile(ctx context.Context, url string) (*remoteFile, error) {
+	header := http.Header{"Range": {"bytes=0-0"}}
+	resp, err := httpGet(ctx, url, header, http.StatusOK, http.StatusPartialContent)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	info := &remoteFile{Size: resp.ContentLength}
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		// Content-Range: bytes 0-0/12345
+		_, total, ok := strings.Cut(resp.Header.Get("Content-Range"), "/")
+		if size, err := strconv.ParseInt(total, 10, 64); ok && err == nil && size > 0 {
+			info.Size = size
+			info.AcceptRanges = true
+		}
+	}
+
+	// If-Range 只能使用强 ETag 或 Last-Modified
+	if etag := resp.Header.Get("ETag"); etag != "" && !strings.HasPrefix(etag, "W/") {
+		info.Validator = etag
+	} else {
+		info.Validator = resp.Header.Get("Last-Modified")
+	}
+	return info, nil
+}
+
+// downloadSegment 下载一个分段，从已写入的位置继续，失败后由调用方重试
+func downloadSegment(ctx context.Context, url string, file *os.File, state *partialDownload, s *segment, pr *ProgressReader) error {
+	state.mu.Lock()
+	offset := s.Start + s.Done
+	state.mu.Unlock()
+
+	header := http.Header{"Range": {fmt.Sprintf("bytes=%d-%d", offset, s.End)}}
+	if state.Validator != "" {
+		header.Set("If-Range", state.Validator)
+	}
+
+	resp, err := doGet(ctx, url, header)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	// 远端文件变化时服务器会忽略 Range 返回 200
+	if resp.StatusCode != http.StatusPartialContent {
+		return &StatusError{URL: url, Status: resp.Status, Code: resp.StatusCode}
+	}
+
+	buf := make([]byte, 32*1024)
+	for offset <= s.End {
+		n, err := resp.Body.Read(buf)
+		if n > 0 {
+			if int64(n) > s.End-offset+1 {
+				n = int(s.End - offset + 1)
+			}
+			if _, werr := file.WriteAt(buf[:n], offset); werr != nil {
+				return werr
+			}
+			offset += int64(n)
+			state.mu.Lock()
+			s.Done += int64(n)
+			state.mu.Unlock()
+			pr.Add(int64(n))
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if offset <= s.End {
+		return fmt.Errorf("分段下载不完整: %d-%d", offset, s.End)
+	}
+	return nil
+}
+
+// downloadSegmented 分段并发下载，中断后保留 .part 文件与状态，下次从已下载的位置继续
+func (fm *FrpsManager) downloadSegmented(ctx context.Context, url, filename, description string, info *remoteFile) error {
+	state := loadPartialDownload(filename, info.Size, info.Validator)
+	if state == nil {
+		removePartialDownload(filename)
+		state = newPartialDownload(info.Size, info.Validator)
+	}
+
+	file, err := os.OpenFile(filename+partSuffix, os.O_RDWR|os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if err := file.Truncate(info.Size); err != nil {
+		return err
+	}
+
+	done := state.done()
+	if done > 0 {
+		fm.Colors["yellow"].Printf("检测到未完成的下载，从 %s 处继续...\n", fm.formatBytes(done))
+	}
+
+	pr := NewProgressReader(nil, info.Size)
+	pr.Add(done)
+	pr.StartProgress(description, fm)
+
+	// 定期保存下载状态，进程被中断时也能从最近的位置继续
+	statePath := filename + partStateSuffix
+	stopSaving := make(chan struct{})
+	savingDone := make(chan struct{})
+	go func() {
+		defer close(savingDone)
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				state.save(statePath)
+			case <-stopSaving:
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	errs := make(chan error, len(state.Segments))
+	for _, s := range state.Segments {
+		if s.remaining() == 0 {
+			continue
+		}
+		wg.Add(1)
+		go func(s *segment) {
+			defer wg.Done()
+			err := withRetry(ctx, func() error {
+				return downloadSegment(ctx, url, file, state, s, pr)
+			})
+			if err != nil {
+				errs <- err
+			}
+		}(s)
+	}
+	wg.Wait()
+	close(stopSaving)
+	<-savingDone
+	close(errs)
+	pr.Finish()
+
+	if err := <-errs; err != nil {
+		state.save(statePath)
+		return err
+	}
+
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(filename+partSuffix, filename); err != nil {
+		return err
+	}
+	os.Remove(statePath)
+	return nil
+}
+
+// downloadStream 服务器不支持 Range 时从头下载，失败后重新下载
+func (fm *FrpsManager) downloadStream(ctx context.Context, url, filename, description string) error {
+	removePartialDownload(filename)
+	return withRetry(ctx, func() error {
+		return fm.downloadStreamOnce(ctx, url, filename, description)
+	})
+}
+
+// downloadStreamOnce 完整下载一次文件
+func (fm *FrpsManager) downloadStreamOnce(ctx context.Context, url, filename, description string) error {
+	resp, err := doGet(ctx, url, nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return &StatusError{URL: url, Status: resp.Status, Code: resp.StatusCode}
+	}
+
+	// 获取文件大小
+	total := resp.ContentLength
+	if total <= 0 {
+		total = 1 // 避免除零错误，对于未知大小的文件
+	}
+
+	tmpFile, err := os.Create(filename + partSuffix)
+	if err != nil {
+		return err
+	}
+	defer tmpFile.Close()
+
+	progressReader := NewProgressReader(resp.Body, total)
+	progressReader.StartProgress(description, fm)
+
+	_, err = io.Copy(tmpFile, progressReader)
+	progressReader.Finish()
+	if err != nil {
+		return err
+	}
+
+	if err := tmpFile.Close(); err != nil {
+		return err
+	}
+	return os.Rename(filename+partSuffix, filename)
+}
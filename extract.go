@@ -0,0 +1,258 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// maxEntrySize 发布包中单个文件的大小上限，frps 约 15MB
+const maxEntrySize = 256 << 20
+
+// extractMember 需要从发布包中提取的文件
+type extractMember struct {
+	Name     string      // 包内文件名，位于包的根目录或第一层目录中
+	Dest     string      // 安装路径
+	Mode     os.FileMode // 安装后的权限，不使用包内记录的权限
+	Required bool        // 发布包中缺少该文件时报错
+}
+
+// releaseMembers 安装组件时从发布包中提取的文件，frps 与 frpc 来自同一个发布包
+func releaseMembers(c *Component) []extractMember {
+	return []extractMember{
+		{Name: c.Name, Dest: c.BinaryPath(), Mode: 0755, Required: true},
+		{Name: "LICENSE", Dest: filepath.Join(c.Dir, "LICENSE"), Mode: 0644},
+	}
+}
+
+// ArchiveError 发布包内容不符合要求，重新下载也无法解决
+type ArchiveError struct {
+	Message string
+}
+
+func (e *ArchiveError) Error() string {
+	return e.Message
+}
+
+func archiveErrorf(format string, args ...interface{}) error {
+	return &ArchiveError{Message: fmt.Sprintf(format, args...)}
+}
+
+// extraction 已解压到临时文件、尚未安装的文件
+type extraction struct {
+	files    map[string]string // 安装路径 -> 临时文件
+	modes    map[string]os.FileMode
+	checksum string // 边下载边解压时计算的发布包 SHA-256
+}
+
+// Commit 将临时文件移动到安装路径
+func (e *extraction) Commit() error {
+	for dest, tmp := range e.files {
+		if err := os.Chmod(tmp, e.modes[dest]); err != nil {
+			return err
+		}
+		if err := os.Rename(tmp, dest); err != nil {
+			return err
+		}
+		delete(e.files, dest)
+	}
+	return nil
+}
+
+// Cleanup 删除尚未安装的临时文件
+func (e *extraction) Cleanup() {
+	for dest, tmp := range e.files {
+		os.Remove(tmp)
+		delete(e.files, dest)
+	}
+}
+
+// safeEntryName 检查包内路径，拒绝绝对路径与跳出解压目录的路径
+func safeEntryName(name string) (string, error) {
+	if name == "" || path.IsAbs(name) || strings.Contains(name, `\`) {
+		return "", archiveErrorf("发布包包含不安全的路径: %q", name)
+	}
+	for _, part := range strings.Split(name, "/") {
+		if part == ".." {
+			return "", archiveErrorf("发布包包含不安全的路径: %q", name)
+		}
+	}
+	return path.Clean(name), nil
+}
+
+// matchMember 返回包内路径对应的待提取文件，只匹配包的根目录或第一层目录
+func matchMember(name string, members []extractMember) *extractMember {
+	dir, base := path.Split(name)
+	if strings.Count(strings.TrimSuffix(dir, "/"), "/") > 0 {
+		return nil
+	}
+	for i := range members {
+		if members[i].Name == base {
+			return &members[i]
+		}
+	}
+	return nil
+}
+
+// extractRelease 从 tar.gz 数据流中提取指定的文件到临时文件，可以直接读取下载的响应体，
+// 调用方校验通过后 Commit 安装，失败时 Cleanup
+func extractRelease(r io.Reader, members []extractMember) (*extraction, error) {
+	gzr, err := gzip.NewReader(r)
+	if errors.Is(err, gzip.ErrHeader) {
+		return nil, archiveErrorf("发布包不是有效的 tar.gz 文件")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("读取发布包失败: %v", err)
+	}
+	defer gzr.Close()
+
+	e := &extraction{files: make(map[string]string), modes: make(map[string]os.FileMode)}
+	tr := tar.NewReader(gzr)
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			e.Cleanup()
+			return nil, fmt.Errorf("读取发布包失败: %v", err)
+		}
+
+		name, err := safeEntryName(header.Name)
+		if err != nil {
+			e.Cleanup()
+			return nil, err
+		}
+		member := matchMember(name, members)
+		if member == nil || header.Typeflag == tar.TypeDir {
+			continue
+		}
+		if header.Typeflag != tar.TypeReg {
+			e.Cleanup()
+			return nil, archiveErrorf("发布包中的 %s 不是普通文件 (可能是链接)，拒绝安装", name)
+		}
+		if _, ok := e.files[member.Dest]; ok {
+			e.Cleanup()
+			return nil, archiveErrorf("发布包中有多个 %s", member.Name)
+		}
+		if header.Size > maxEntrySize {
+			e.Cleanup()
+			return nil, archiveErrorf("发布包中的 %s 过大 (%d 字节)", name, header.Size)
+		}
+
+		tmp, err := extractEntry(tr, member.Dest)
+		if err != nil {
+			e.Cleanup()
+			return nil, fmt.Errorf("解压 %s 失败: %v", name, err)
+		}
+		e.files[member.Dest] = tmp
+		e.modes[member.Dest] = member.Mode
+	}
+
+	for _, member := range members {
+		if _, ok := e.files[member.Dest]; member.Required && !ok {
+			e.Cleanup()
+			return nil, archiveErrorf("发布包中没有 %s，请确认下载的是 frp 的 linux 发布包", member.Name)
+		}
+	}
+	return e, nil
+}
+
+// extractEntry 将当前文件写入安装目录中的临时文件，超过大小上限时报错
+func extractEntry(r io.Reader, dest string) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(dest), 0755); err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp(filepath.Dir(dest), "."+filepath.Base(dest)+".*")
+	if err != nil {
+		return "", err
+	}
+
+	n, err := io.Copy(file, io.LimitReader(r, maxEntrySize+1))
+	if err == nil && n > maxEntrySize {
+		err = fmt.Errorf("超过 %d 字节", maxEntrySize)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// extractArchive 从本地发布包中提取文件
+func extractArchive(filename string, members []extractMember) (*extraction, error) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return extractRelease(file, members)
+}
+
+// downloadAndExtract 边下载边解压，同时计算 SHA-256，不在磁盘上保存发布包，失败后重新下载
+func (fm *FrpsManager) downloadAndExtract(ctx context.Context, url, description string, members []extractMember) (*extraction, error) {
+	var e *extraction
+	err := withRetry(ctx, func() error {
+		var err error
+		e, err = fm.downloadAndExtractOnce(ctx, url, description, members)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// downloadAndExtractOnce 下载并解压一次
+func (fm *FrpsManager) downloadAndExtractOnce(ctx context.Context, url, description string, members []extractMember) (*extraction, error) {
+	resp, err := doGet(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{URL: url, Status: resp.Status, Code: resp.StatusCode}
+	}
+
+	total := resp.ContentLength
+	if total <= 0 {
+		total = 1 // 避免除零错误，对于未知大小的文件
+	}
+	progressReader := NewProgressReader(resp.Body, total)
+	progressReader.StartProgress(description, fm)
+
+	hash := sha256.New()
+	body := io.TeeReader(progressReader, hash)
+	e, err := extractRelease(body, members)
+	if err == nil {
+		// 读完 tar 结尾之后的数据，校验值覆盖整个发布包
+		_, err = io.Copy(io.Discard, body)
+		if err != nil {
+			e.Cleanup()
+		}
+	}
+	progressReader.Finish()
+	if err != nil {
+		return nil, err
+	}
+
+	e.checksum = hex.EncodeToString(hash.Sum(nil))
+	return e, nil
+}
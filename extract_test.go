@@ -0,0 +1,185 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// tarEntry 测试用发布包中的一项
+type tarEntry struct {
+	name     string
+	typeflag byte
+	body     string
+	size     int64 // 非 0 时只写入头部，用于构造声明过大的文件
+	linkname string
+}
+
+// buildTarGz 按顺序生成 tar.gz 数据
+func buildTarGz(t *testing.T, entries []tarEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gzw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gzw)
+	for _, e := range entries {
+		typeflag := e.typeflag
+		if typeflag == 0 {
+			typeflag = tar.TypeReg
+		}
+		header := &tar.Header{Name: e.name, Typeflag: typeflag, Mode: 0755, Linkname: e.linkname}
+		if typeflag == tar.TypeReg {
+			header.Size = int64(len(e.body))
+		}
+		if e.size != 0 {
+			// 只写入头部，读取方应在读取内容之前拒绝
+			header.Size = e.size
+			if err := tw.WriteHeader(header); err != nil {
+				t.Fatal(err)
+			}
+			if err := gzw.Close(); err != nil {
+				t.Fatal(err)
+			}
+			return buf.Bytes()
+		}
+		if err := tw.WriteHeader(header); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gzw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestSafeEntryName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"frp_0.61.0_linux_amd64/frps", "frp_0.61.0_linux_amd64/frps", true},
+		{"./frps", "frps", true},
+		{"frp_0.61.0_linux_amd64//frps", "frp_0.61.0_linux_amd64/frps", true},
+		{"", "", false},
+		{"../x", "", false},
+		{"frp/../../x", "", false},
+		{"/etc/frps", "", false},
+		{`frp\..\frps`, "", false},
+	}
+	for _, tt := range tests {
+		got, err := safeEntryName(tt.name)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("safeEntryName(%q) = %q, %v; want %q, ok=%v", tt.name, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestMatchMember(t *testing.T) {
+	members := []extractMember{{Name: "frps"}, {Name: "LICENSE"}}
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"frps", "frps"},
+		{"frp_0.61.0_linux_amd64/frps", "frps"},
+		{"frp_0.61.0_linux_amd64/LICENSE", "LICENSE"},
+		{"frp_0.61.0_linux_amd64/sub/frps", ""},
+		{"frp_0.61.0_linux_amd64/frpc", ""},
+		{"frp_0.61.0_linux_amd64/frps.toml", ""},
+	}
+	for _, tt := range tests {
+		got := ""
+		if m := matchMember(tt.name, members); m != nil {
+			got = m.Name
+		}
+		if got != tt.want {
+			t.Errorf("matchMember(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestExtractRelease(t *testing.T) {
+	const dir = "frp_0.61.0_linux_amd64/"
+	tests := []struct {
+		name    string
+		entries []tarEntry
+		wantErr bool
+	}{
+		{"ok", []tarEntry{
+			{name: dir, typeflag: tar.TypeDir},
+			{name: dir + "frpc", body: "frpc"},
+			{name: dir + "frps", body: "frps-binary"},
+			{name: dir + "LICENSE", body: "license"},
+		}, false},
+		{"optional member missing", []tarEntry{{name: dir + "frps", body: "frps-binary"}}, false},
+		{"parent path", []tarEntry{{name: "../x", body: "x"}, {name: dir + "frps", body: "frps-binary"}}, true},
+		{"absolute path", []tarEntry{{name: "/usr/local/frps/frps", body: "x"}}, true},
+		{"symlink binary", []tarEntry{{name: dir + "frps", typeflag: tar.TypeSymlink, linkname: "/etc/passwd"}}, true},
+		{"hard link binary", []tarEntry{{name: dir + "frps", typeflag: tar.TypeLink, linkname: dir + "frpc"}}, true},
+		{"duplicate binary", []tarEntry{{name: dir + "frps", body: "a"}, {name: "frps", body: "b"}}, true},
+		{"oversized binary", []tarEntry{{name: dir + "frps", size: maxEntrySize + 1}}, true},
+		{"missing binary", []tarEntry{{name: dir + "frpc", body: "frpc"}, {name: dir + "LICENSE", body: "license"}}, true},
+		{"binary too deep", []tarEntry{{name: dir + "bin/frps", body: "frps-binary"}}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			installDir := t.TempDir()
+			c := &Component{Name: "frps", Dir: installDir}
+			e, err := extractRelease(bytes.NewReader(buildTarGz(t, tt.entries)), releaseMembers(c))
+			if tt.wantErr {
+				var archiveErr *ArchiveError
+				if !errors.As(err, &archiveErr) {
+					t.Fatalf("err = %v, want *ArchiveError", err)
+				}
+				assertNoTempFiles(t, installDir)
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := e.Commit(); err != nil {
+				t.Fatal(err)
+			}
+			content, err := os.ReadFile(c.BinaryPath())
+			if err != nil || string(content) != "frps-binary" {
+				t.Fatalf("installed binary = %q, %v", content, err)
+			}
+			if info, err := os.Stat(c.BinaryPath()); err != nil || info.Mode().Perm() != 0755 {
+				t.Fatalf("binary mode = %v, %v", info.Mode(), err)
+			}
+			if _, err := os.Stat(filepath.Join(installDir, "frpc")); !os.IsNotExist(err) {
+				t.Fatalf("frpc should not be extracted: %v", err)
+			}
+		})
+	}
+}
+
+func TestExtractReleaseNotGzip(t *testing.T) {
+	_, err := extractRelease(bytes.NewReader([]byte("<html>not found</html>")), releaseMembers(&Component{Name: "frps", Dir: t.TempDir()}))
+	var archiveErr *ArchiveError
+	if !errors.As(err, &archiveErr) {
+		t.Fatalf("err = %v, want *ArchiveError", err)
+	}
+}
+
+// assertNoTempFiles 检查失败后没有留下临时文件
+func assertNoTempFiles(t *testing.T, dir string) {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, entry := range entries {
+		t.Errorf("unexpected file left behind: %s", entry.Name())
+	}
+}
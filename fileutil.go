@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"syscall"
+)
+
+// serviceUser 服务运行的用户，各服务管理器的服务定义都没有指定用户，以 root 运行
+const serviceUser = "root"
+
+// secretFileMode 包含 token、密码等敏感信息的文件的权限，只有服务用户可读写
+const secretFileMode os.FileMode = 0600
+
+// serviceOwner 返回服务用户的 uid 与 gid，查询失败时为 root
+func serviceOwner() (int, int) {
+	u, err := user.Lookup(serviceUser)
+	if err != nil {
+		return 0, 0
+	}
+	uid, err1 := strconv.Atoi(u.Uid)
+	gid, err2 := strconv.Atoi(u.Gid)
+	if err1 != nil || err2 != nil {
+		return 0, 0
+	}
+	return uid, gid
+}
+
+// createTempFile 在 path 所在目录创建临时文件，由 fill 写入内容并 fsync。
+// 以 root 运行且权限为 secretFileMode 时临时文件归服务用户所有，替换后目标文件不会有短暂的错误权限
+func createTempFile(path string, perm os.FileMode, fill func(f *os.File) error) (string, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	file, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return "", err
+	}
+
+	err = fill(file)
+	if err == nil {
+		err = file.Chmod(perm)
+	}
+	if err == nil && perm == secretFileMode && os.Geteuid() == 0 {
+		uid, gid := serviceOwner()
+		err = file.Chown(uid, gid)
+	}
+	if err == nil {
+		err = file.Sync()
+	}
+	if cerr := file.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(file.Name())
+		return "", err
+	}
+	return file.Name(), nil
+}
+
+// commitTempFile 将 createTempFile 创建的临时文件重命名为 path，并 fsync 所在目录使重命名落盘
+func commitTempFile(tmp, path string) error {
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
+
+// syncDir fsync 目录
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+	if err := d.Sync(); err != nil && err != syscall.EINVAL {
+		// 部分文件系统不支持对目录 fsync
+		return err
+	}
+	return nil
+}
+
+// writeFileAtomic 原子地写入文件：先写入同目录的临时文件，完成后重命名替换，中断时不会留下写了一半的文件
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp, err := createTempFile(path, perm, func(f *os.File) error {
+		_, err := f.Write(data)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	return commitTempFile(tmp, path)
+}
+
+// fixSecretPermissions 修正已安装的配置文件、环境变量文件与配置历史的权限，旧版本以 0644 写入配置文件
+func (fm *FrpsManager) fixSecretPermissions() {
+	if os.Geteuid() != 0 {
+		return
+	}
+	uid, gid := serviceOwner()
+	var paths []string
+	for _, c := range []*Component{ServerComponent, ClientComponent} {
+		paths = append(paths, c.ConfigPath(), c.EnvFile())
+		if files, err := filepath.Glob(filepath.Join(c.HistoryDir(), "*")); err == nil {
+			paths = append(paths, files...)
+		}
+	}
+
+	for _, path := range paths {
+		info, err := os.Lstat(path)
+		if err != nil || !info.Mode().IsRegular() {
+			continue
+		}
+		stat, ok := info.Sys().(*syscall.Stat_t)
+		if info.Mode().Perm() == secretFileMode && ok && int(stat.Uid) == uid && int(stat.Gid) == gid {
+			continue
+		}
+		if err := os.Chown(path, uid, gid); err != nil {
+			fm.Colors["yellow"].Printf("警告：修正 %s 的所有者失败: %v\n", path, err)
+			continue
+		}
+		if err := os.Chmod(path, secretFileMode); err != nil {
+			fm.Colors["yellow"].Printf("警告：修正 %s 的权限失败: %v\n", path, err)
+			continue
+		}
+		fm.Colors["yellow"].Printf("已将 %s 的权限修正为 %04o (%s)\n", path, secretFileMode, serviceUser)
+	}
+}
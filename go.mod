@@ -2,7 +2,12 @@ module frps-onekey
 
 go 1.19
 
-require github.com/fatih/color v1.15.0
+require (
+	github.com/BurntSushi/toml v1.4.0
+	github.com/fatih/color v1.15.0
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require (
 	github.com/mattn/go-colorable v0.1.13 // indirect
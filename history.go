@@ -0,0 +1,370 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultHistoryDir 配置文件历史版本目录，按组件名分开存放，卸载后仍然保留
+const DefaultHistoryDir = "/var/lib/frps-onekey/history"
+
+// maxConfigHistory 每个组件最多保留的历史版本数，超出时删除最早的版本
+const maxConfigHistory = 100
+
+// HistoryDir 返回配置文件历史版本的目录
+func (c *Component) HistoryDir() string {
+	return filepath.Join(DefaultHistoryDir, c.Name)
+}
+
+// configVersion 配置文件的一个历史版本，内容保存在 <ID>.toml，本结构保存在 <ID>.json
+type configVersion struct {
+	ID      int       `json:"id"`
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"` // 写入该版本的命令
+	SHA256  string    `json:"sha256"`
+}
+
+// contentPath 返回历史版本内容的路径
+func (v configVersion) contentPath(dir string) string {
+	return filepath.Join(dir, strconv.Itoa(v.ID)+".toml")
+}
+
+// metaPath 返回历史版本元数据的路径
+func (v configVersion) metaPath(dir string) string {
+	return filepath.Join(dir, strconv.Itoa(v.ID)+".json")
+}
+
+// listConfigHistory 列出历史版本，按 ID 升序排列，目录不存在时返回空
+func listConfigHistory(dir string) ([]configVersion, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var versions []configVersion
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		var v configVersion
+		if err := json.Unmarshal(data, &v); err != nil {
+			return nil, fmt.Errorf("解析 %s 失败: %v", file, err)
+		}
+		versions = append(versions, v)
+	}
+	sort.Slice(versions, func(i, j int) bool { return versions[i].ID < versions[j].ID })
+	return versions, nil
+}
+
+// findConfigVersion 按 ID 查找历史版本
+func findConfigVersion(versions []configVersion, id string) (configVersion, error) {
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return configVersion{}, fmt.Errorf("无效的版本号: %s", id)
+	}
+	for _, v := range versions {
+		if v.ID == n {
+			return v, nil
+		}
+	}
+	return configVersion{}, fmt.Errorf("历史版本 %d 不存在", n)
+}
+
+// sha256Hex 返回内容的 SHA-256 十六进制值
+func sha256Hex(content []byte) string {
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:])
+}
+
+// saveConfigVersion 将内容保存为新的历史版本，与最新版本相同时不重复保存
+func saveConfigVersion(dir string, content []byte, command string) (*configVersion, error) {
+	versions, err := listConfigHistory(dir)
+	if err != nil {
+		return nil, err
+	}
+	sum := sha256Hex(content)
+	if n := len(versions); n > 0 && versions[n-1].SHA256 == sum {
+		return nil, nil
+	}
+	// 历史版本中包含 token 等敏感信息，只允许 root 读取
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	v := configVersion{ID: 1, Time: time.Now(), Command: command, SHA256: sum}
+	if n := len(versions); n > 0 {
+		v.ID = versions[n-1].ID + 1
+	}
+	meta, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if err := writeFileAtomic(v.contentPath(dir), content, secretFileMode); err != nil {
+		return nil, err
+	}
+	// 元数据最后写入，中断时不会留下没有内容的版本
+	if err := writeFileAtomic(v.metaPath(dir), meta, secretFileMode); err != nil {
+		os.Remove(v.contentPath(dir))
+		return nil, err
+	}
+
+	versions = append(versions, v)
+	for len(versions) > maxConfigHistory {
+		os.Remove(versions[0].metaPath(dir))
+		os.Remove(versions[0].contentPath(dir))
+		versions = versions[1:]
+	}
+	return &v, nil
+}
+
+// recordConfig 将当前的配置文件保存为历史版本，失败时只给出警告，不影响配置的修改
+func (fm *FrpsManager) recordConfig(command string) {
+	content, err := os.ReadFile(fm.Component.ConfigPath())
+	if err != nil {
+		if !os.IsNotExist(err) {
+			fm.Colors["yellow"].Printf("警告：保存配置历史失败: %v\n", err)
+		}
+		return
+	}
+	v, err := saveConfigVersion(fm.Component.HistoryDir(), content, command)
+	if err != nil {
+		fm.Colors["yellow"].Printf("警告：保存配置历史失败: %v\n", err)
+		return
+	}
+	if v != nil {
+		fm.Colors["green"].Printf("✓ 已保存为历史版本 %d\n", v.ID)
+	}
+}
+
+// recordExistingConfig 修改配置前调用，还没有历史记录时先保存现有的配置，以便回滚到修改之前
+func (fm *FrpsManager) recordExistingConfig() {
+	if versions, err := listConfigHistory(fm.Component.HistoryDir()); err == nil && len(versions) > 0 {
+		return
+	}
+	fm.recordConfig("已有配置")
+}
+
+// ConfigHistory 列出配置文件的历史版本，* 标记与当前配置相同的版本
+func (fm *FrpsManager) ConfigHistory() {
+	dir := fm.Component.HistoryDir()
+	versions, err := listConfigHistory(dir)
+	if err != nil {
+		fm.Colors["red"].Printf("读取配置历史失败: %v\n", err)
+		return
+	}
+
+	fmt.Printf("============== %s 配置历史 (%s) ==============\n", fm.Component.Name, dir)
+	if len(versions) == 0 {
+		fm.Colors["yellow"].Println("  暂无历史版本")
+		return
+	}
+	current := ""
+	if content, err := os.ReadFile(fm.Component.ConfigPath()); err == nil {
+		current = sha256Hex(content)
+	}
+	for _, v := range versions {
+		mark := " "
+		if v.SHA256 == current {
+			mark = "*"
+		}
+		fmt.Printf("%s %4d  %s  %s  %s\n", mark, v.ID, v.Time.Format("2006-01-02 15:04:05"), v.SHA256[:12], v.Command)
+	}
+}
+
+// readConfigVersion 读取历史版本的内容，current 表示当前的配置文件，并检查内容与记录的校验值一致
+func (fm *FrpsManager) readConfigVersion(versions []configVersion, id string) (string, []byte, error) {
+	if id == "current" {
+		content, err := os.ReadFile(fm.Component.ConfigPath())
+		return fm.Component.ConfigPath(), content, err
+	}
+	v, err := findConfigVersion(versions, id)
+	if err != nil {
+		return "", nil, err
+	}
+	path := v.contentPath(fm.Component.HistoryDir())
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", nil, err
+	}
+	if sha256Hex(content) != v.SHA256 {
+		return "", nil, fmt.Errorf("历史版本 %d 的内容与记录的校验值不一致，文件可能已损坏", v.ID)
+	}
+	return fmt.Sprintf("版本 %d (%s)", v.ID, v.Command), content, nil
+}
+
+// ConfigDiff 比较两个历史版本，b 为 current 时与当前的配置文件比较
+func (fm *FrpsManager) ConfigDiff(a, b string) {
+	versions, err := listConfigHistory(fm.Component.HistoryDir())
+	if err != nil {
+		fm.Colors["red"].Printf("读取配置历史失败: %v\n", err)
+		return
+	}
+	nameA, contentA, err := fm.readConfigVersion(versions, a)
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	nameB, contentB, err := fm.readConfigVersion(versions, b)
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+
+	lines := unifiedDiff(splitLines(contentA), splitLines(contentB), 3)
+	if len(lines) == 0 {
+		fm.Colors["green"].Println("两个版本的内容相同")
+		return
+	}
+	fmt.Printf("--- %s\n+++ %s\n", nameA, nameB)
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "@@"):
+			fm.Colors["blue"].Println(line)
+		case strings.HasPrefix(line, "-"):
+			fm.Colors["red"].Println(line)
+		case strings.HasPrefix(line, "+"):
+			fm.Colors["green"].Println(line)
+		default:
+			fmt.Println(line)
+		}
+	}
+}
+
+// ConfigRollback 校验历史版本后替换当前的配置文件并重启服务
+func (fm *FrpsManager) ConfigRollback(id string) {
+	if !fm.checkRoot() {
+		return
+	}
+	versions, err := listConfigHistory(fm.Component.HistoryDir())
+	if err != nil {
+		fm.Colors["red"].Printf("读取配置历史失败: %v\n", err)
+		return
+	}
+	if id == "current" {
+		fm.Colors["red"].Println("请指定要回滚到的历史版本号")
+		return
+	}
+	name, content, err := fm.readConfigVersion(versions, id)
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	if !fm.confirm(fmt.Sprintf("确认将 %s 配置回滚到%s？(y/n): ", fm.Component.Name, name)) {
+		fm.Colors["yellow"].Println("已取消回滚。")
+		return
+	}
+
+	if err := fm.replaceConfig(content, "config rollback "+id); err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	fm.Colors["green"].Printf("已回滚到%s\n", name)
+	if fm.isInstalled() {
+		fm.Restart()
+	} else {
+		fm.Colors["yellow"].Printf("%s 服务未运行，请使用 '%s start' 启动服务\n", fm.Component.Name, fm.Component.Command)
+	}
+}
+
+// splitLines 将内容拆分为行，忽略结尾的换行
+func splitLines(content []byte) []string {
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return nil
+	}
+	return strings.Split(text, "\n")
+}
+
+// unifiedDiff 按最长公共子序列比较两组行，返回统一格式的差异，每处修改前后保留 context 行上下文
+func unifiedDiff(a, b []string, context int) []string {
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else if lcs[i+1][j] >= lcs[i][j+1] {
+				lcs[i][j] = lcs[i+1][j]
+			} else {
+				lcs[i][j] = lcs[i][j+1]
+			}
+		}
+	}
+
+	// 逐行生成编辑操作，op 为 ' '、'-' 或 '+'
+	type edit struct {
+		op   byte
+		line string
+		ai   int // 该行之前 a 中已处理的行数
+		bi   int
+	}
+	var edits []edit
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			edits = append(edits, edit{' ', a[i], i, j})
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			edits = append(edits, edit{'-', a[i], i, j})
+			i++
+		default:
+			edits = append(edits, edit{'+', b[j], i, j})
+			j++
+		}
+	}
+
+	// 将相距不超过 2*context 行的修改合并为一段
+	var out []string
+	for start := 0; start < len(edits); {
+		if edits[start].op == ' ' {
+			start++
+			continue
+		}
+		from := start - context
+		if from < 0 {
+			from = 0
+		}
+		end, same := start, 0
+		for end < len(edits) && same <= 2*context {
+			if edits[end].op == ' ' {
+				same++
+			} else {
+				same = 0
+			}
+			end++
+		}
+		to := end - same + context
+		if to > len(edits) {
+			to = len(edits)
+		}
+
+		var lines []string
+		countA, countB := 0, 0
+		for _, e := range edits[from:to] {
+			lines = append(lines, string(e.op)+e.line)
+			if e.op != '+' {
+				countA++
+			}
+			if e.op != '-' {
+				countB++
+			}
+		}
+		out = append(out, fmt.Sprintf("@@ -%d,%d +%d,%d @@", edits[from].ai+1, countA, edits[from].bi+1, countB))
+		out = append(out, lines...)
+		start = to
+	}
+	return out
+}
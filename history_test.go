@@ -0,0 +1,60 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSplitLines(t *testing.T) {
+	tests := []struct {
+		content string
+		want    []string
+	}{
+		{"", nil},
+		{"\n", nil},
+		{"a", []string{"a"}},
+		{"a\nb\n", []string{"a", "b"}},
+		{"a\n\nb", []string{"a", "", "b"}},
+	}
+	for _, tt := range tests {
+		if got := splitLines([]byte(tt.content)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitLines(%q) = %q, want %q", tt.content, got, tt.want)
+		}
+	}
+}
+
+func TestUnifiedDiff(t *testing.T) {
+	tests := []struct {
+		name    string
+		a, b    string
+		context int
+		want    []string
+	}{
+		{"identical", "a\nb\n", "a\nb\n", 3, nil},
+		{"change", "a\nb\nc\nd\ne\n", "a\nb\nX\nd\ne\n", 1, []string{
+			"@@ -2,3 +2,3 @@", " b", "-c", "+X", " d",
+		}},
+		{"append", "a\n", "a\nb\n", 3, []string{
+			"@@ -1,1 +1,2 @@", " a", "+b",
+		}},
+		{"delete", "a\nb\nc\n", "a\nc\n", 3, []string{
+			"@@ -1,3 +1,2 @@", " a", "-b", " c",
+		}},
+		{"separate hunks", "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n", "1\nX\n3\n4\n5\n6\n7\n8\nY\n10\n", 1, []string{
+			"@@ -1,3 +1,3 @@", " 1", "-2", "+X", " 3",
+			"@@ -8,3 +8,3 @@", " 8", "-9", "+Y", " 10",
+		}},
+		{"nearby changes merged", "1\n2\n3\n4\n5\n", "X\n2\n3\n4\nY\n", 2, []string{
+			"@@ -1,5 +1,5 @@", "-1", "+X", " 2", " 3", " 4", "-5", "+Y",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := unifiedDiff(splitLines([]byte(tt.a)), splitLines([]byte(tt.b)), tt.context)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
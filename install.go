@@ -1,17 +1,14 @@
 package main
 
 import (
-	"archive/tar"
+	"context"
 	"bufio"
-	"compress/gzip"
 	"encoding/json"
 	"fmt"
 	"io"
 	"math/rand"
 	"net"
-	"net/http"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strconv"
 	"strings"
@@ -19,23 +16,45 @@ import (
 )
 
 // Install 安装 frps
-func (fm *FrpsManager) Install() {
+func (fm *FrpsManager) Install(ctx context.Context, opts *InstallOptions) {
 	if !fm.checkRoot() {
 		return
 	}
+	configureNetwork(opts.NetworkOptions)
 
 	fm.showBanner()
 	
+	// 合并应答文件与命令行参数/环境变量，命令行参数与环境变量优先
+	answers := &Answers{}
+	present := make(map[string]bool)
+	if opts.AnswersFile != "" {
+		var err error
+		answers, present, err = loadAnswersFile(opts.AnswersFile)
+		if err != nil {
+			fm.Colors["red"].Printf("应答文件 %s 无效:\n%v\n", opts.AnswersFile, err)
+			return
+		}
+		fm.Colors["green"].Printf("已加载应答文件: %s\n", opts.AnswersFile)
+	}
+	mergeConfig(&answers.Config, present, &opts.Config, opts.Present)
+	
+	// 提供应答文件时进入无人值守模式，必填项必须齐全
+	if opts.AnswersFile != "" {
+		fm.NonInteractive = true
+		if errs := missingRequired(present); len(errs) > 0 {
+			fm.Colors["red"].Printf("应答文件 %s 无效:\n%v\n", opts.AnswersFile, errs)
+			return
+		}
+	}
+	if opts.Source == "" {
+		opts.Source = answers.DownloadSource
+	}
+	
 	// 检查是否已经安装
-	if fm.isInstalled() {
+	running := fm.isInstalled()
+	if running {
 		fm.Colors["green"].Println("frps 已经安装并正在运行。")
-		fmt.Print("是否要重新安装 frps? (y/n): ")
-		
-		reader := bufio.NewReader(os.Stdin)
-		choice, _ := reader.ReadString('\n')
-		choice = strings.TrimSpace(strings.ToLower(choice))
-		
-		if choice != "y" && choice != "yes" {
+		if !fm.confirm("是否要重新安装 frps? (y/n): ") {
 			fm.Colors["yellow"].Println("跳过安装。")
 			return
 		}
@@ -43,118 +62,188 @@ func (fm *FrpsManager) Install() {
 
 	fm.Colors["green"].Println("开始安装 frps...")
 	
-	// 安装依赖包
-	if err := fm.installDependencies(); err != nil {
-		fm.Colors["red"].Printf("安装依赖包失败: %v\n", err)
-		return
-	}
+	var mirror *Mirror
+	var serverIP string
+	if opts.FromArchive != "" {
+		// 离线安装：版本与架构来自发布包，服务器 IP 从本机网卡获取
+		fm.Offline = true
+		if err := fm.inspectArchive(&opts.DownloadOptions); err != nil {
+			fm.Colors["red"].Printf("%v\n", err)
+			return
+		}
+		serverIP = localServerIP()
+	} else {
+		// 安装依赖包
+		if err := fm.installDependencies(); err != nil {
+			fm.Colors["red"].Printf("安装依赖包失败: %v\n", err)
+			return
+		}
 
-	// 选择下载源
-	downloadSource := fm.selectDownloadSource()
-	
-	// 获取最新版本
-	if err := fm.getLatestVersion(downloadSource); err != nil {
-		fm.Colors["red"].Printf("获取最新版本失败: %v\n", err)
-		return
-	}
+		// 选择下载源
+		var err error
+		if mirror, err = fm.chooseDownloadSource(ctx, &opts.DownloadOptions); err != nil {
+			fm.Colors["red"].Printf("%v\n", err)
+			return
+		}
+
+		// 获取要安装的版本
+		if err := fm.resolveVersion(ctx, mirror, &opts.DownloadOptions); err != nil {
+			fm.Colors["red"].Printf("获取%s失败: %v\n", versionTarget(&opts.DownloadOptions), err)
+			return
+		}
 
-	// 获取服务器IP
-	serverIP := fm.getServerIP()
+		// 获取服务器IP
+		serverIP = fm.getServerIP(ctx)
+	}
 	fm.Colors["green"].Printf("服务器IP: %s\n", serverIP)
 
-	// 收集用户配置
-	if err := fm.collectUserConfig(serverIP); err != nil {
+	// 收集用户配置，已通过应答文件、命令行参数或环境变量提供的字段不再询问
+	fm.Config = &answers.Config
+	fm.keepInstalledConfig(present)
+	if fm.NonInteractive {
+		fm.applyConfigDefaults(present, serverIP)
+	} else if err := fm.collectUserConfig(serverIP, present); err != nil {
 		fm.Colors["red"].Printf("收集配置失败: %v\n", err)
 		return
 	}
+	// 重新安装时端口由正在运行的 frps 占用，跳过占用检查
+	if errs := fm.validateConfig(!running); len(errs) > 0 {
+		fm.Colors["red"].Printf("配置无效:\n%v\n", errs)
+		return
+	}
 
 	// 显示配置确认
 	fm.showConfigConfirmation(serverIP)
 	
-	fmt.Print("按任意键继续安装...或按 Ctrl+C 取消: ")
-	reader := bufio.NewReader(os.Stdin)
-	reader.ReadString('\n')
+	if !fm.NonInteractive {
+		fmt.Print("按任意键继续安装...或按 Ctrl+C 取消: ")
+		reader := bufio.NewReader(os.Stdin)
+		reader.ReadString('\n')
+	}
 
-	// 执行安装
-	if err := fm.performInstall(downloadSource); err != nil {
+	// 执行安装，安装过程会切换到程序目录，交互式输入的 frpc 配置路径相对于原来的工作目录
+	workDir, _ := os.Getwd()
+	if err := fm.performInstall(ctx, mirror, &opts.DownloadOptions, fm.generateConfigFile); err != nil {
 		fm.Colors["red"].Printf("安装失败: %v\n", err)
 		return
 	}
 
 	fm.Colors["green"].Println("frps 安装完成！")
 	fm.showInstallationSummary(serverIP)
+	fm.offerClientConfig(serverIP, opts.ClientConfig, opts.ClientProtocol, workDir)
 }
 
 // isInstalled 检查是否已安装
 func (fm *FrpsManager) isInstalled() bool {
 	// 检查进程是否运行
-	cmd := exec.Command("pgrep", "-x", ProgramName)
-	return cmd.Run() == nil
+	procs, err := findProcesses(fm.Component.Name)
+	return err == nil && len(procs) > 0
 }
 
-// installDependencies 安装依赖包
+// installDependencies 安装依赖包，只安装系统中缺失的依赖
 func (fm *FrpsManager) installDependencies() error {
-	var installCmd []string
-	
-	switch fm.SystemInfo.OS {
-	case "CentOS", "RHEL", "Rocky", "AlmaLinux":
-		installCmd = []string{"yum", "install", "-y", "wget", "psmisc", "net-tools", "curl"}
-	case "Ubuntu", "Debian":
-		// 先更新包列表
-		if err := exec.Command("apt-get", "-y", "update").Run(); err != nil {
-			return fmt.Errorf("更新包列表失败: %v", err)
-		}
-		installCmd = []string{"apt-get", "-y", "install", "wget", "psmisc", "net-tools", "curl"}
-	default:
-		return fmt.Errorf("不支持的操作系统: %s", fm.SystemInfo.OS)
+	deps := missingDependencies()
+	if len(deps) == 0 {
+		fm.Colors["green"].Println("依赖已齐全，跳过安装。")
+		return nil
+	}
+
+	names := make([]string, 0, len(deps))
+	for _, dep := range deps {
+		names = append(names, dep.name)
+	}
+
+	pm, err := detectPackageManager(fm.SystemInfo)
+	if err != nil {
+		return fmt.Errorf("%v，请手动安装: %s", err, strings.Join(names, ", "))
 	}
 
-	fm.Colors["green"].Println("正在安装依赖包...")
-	cmd := exec.Command(installCmd[0], installCmd[1:]...)
-	return cmd.Run()
+	pkgs := dependencyPackages(deps)
+	fm.Colors["green"].Printf("缺少 %s，正在通过 %s 安装: %s\n", strings.Join(names, ", "), pm.Name, strings.Join(pkgs, " "))
+
+	if err := pm.Refresh(); err != nil {
+		return err
+	}
+	return pm.Install(pkgs)
+}
+
+// confirm 询问用户是否确认，非交互模式下直接返回 true
+func (fm *FrpsManager) confirm(prompt string) bool {
+	if fm.NonInteractive {
+		return true
+	}
+	fmt.Print(prompt)
+	reader := bufio.NewReader(os.Stdin)
+	choice, _ := reader.ReadString('\n')
+	choice = strings.TrimSpace(strings.ToLower(choice))
+	return choice == "y" || choice == "yes"
 }
 
-// selectDownloadSource 选择下载源
-func (fm *FrpsManager) selectDownloadSource() int {
+// chooseDownloadSource 确定下载源，未指定时在交互模式下询问用户，非交互模式下使用优先级最高的下载源
+func (fm *FrpsManager) chooseDownloadSource(ctx context.Context, opts *DownloadOptions) (*Mirror, error) {
+	mirrors, err := loadMirrors(opts)
+	if err != nil {
+		return nil, err
+	}
+	if strings.EqualFold(opts.Source, AutoSource) {
+		return fm.autoSelectMirror(ctx, mirrors, opts)
+	}
+	if opts.Source != "" {
+		return findMirror(mirrors, opts.Source)
+	}
+	if fm.NonInteractive {
+		return mirrors[0], nil
+	}
+	selected := fm.selectDownloadSource(mirrors)
+	if selected == nil {
+		return fm.autoSelectMirror(ctx, mirrors, opts)
+	}
+	return selected, nil
+}
+
+// selectDownloadSource 选择下载源，按优先级排列，默认为优先级最高的下载源，选择 auto 时返回 nil
+func (fm *FrpsManager) selectDownloadSource(mirrors []*Mirror) *Mirror {
 	fmt.Println()
-	fm.Colors["pink"].Println("请选择 frps 下载源:")
-	fmt.Println("[1]. gitee")
-	fmt.Println("[2]. github (默认)")
+	fm.Colors["pink"].Println("请选择下载源:")
+	for i, m := range mirrors {
+		if i == 0 {
+			fmt.Printf("[%d]. %s (默认)\n", i+1, m.Name)
+		} else {
+			fmt.Printf("[%d]. %s\n", i+1, m.Name)
+		}
+	}
+	fmt.Printf("[%d]. %s (测速后自动选择最快的下载源)\n", len(mirrors)+1, AutoSource)
 	
-	fmt.Print("请选择 (1, 2 或 exit，默认[github]): ")
+	fmt.Printf("请选择 (1-%d、名称或 exit，默认[%s]): ", len(mirrors)+1, mirrors[0].Name)
 	reader := bufio.NewReader(os.Stdin)
 	choice, _ := reader.ReadString('\n')
 	choice = strings.TrimSpace(strings.ToLower(choice))
 	
-	switch choice {
-	case "1", "gitee":
-		fmt.Println("-----------------------------------")
-		fm.Colors["yellow"].Println("       您选择了: gitee")
-		fmt.Println("-----------------------------------")
-		return 1
-	case "exit":
+	if choice == "exit" {
 		os.Exit(1)
-		return 0 // This line will never be reached, but Go requires it
-	default:
-		fmt.Println("-----------------------------------")
-		fm.Colors["yellow"].Println("       您选择了: github")
-		fmt.Println("-----------------------------------")
-		return 2
 	}
+	if choice == AutoSource || choice == strconv.Itoa(len(mirrors)+1) {
+		return nil
+	}
+
+	selected := mirrors[0]
+	if n, err := strconv.Atoi(choice); err == nil && n >= 1 && n <= len(mirrors) {
+		selected = mirrors[n-1]
+	} else if m, err := findMirror(mirrors, choice); err == nil {
+		selected = m
+	}
+
+	fmt.Println("-----------------------------------")
+	fm.Colors["yellow"].Printf("       您选择了: %s\n", selected.Name)
+	fmt.Println("-----------------------------------")
+	return selected
 }
 
 // getLatestVersion 获取最新版本
-func (fm *FrpsManager) getLatestVersion(source int) error {
+func (fm *FrpsManager) getLatestVersion(ctx context.Context, mirror *Mirror) error {
 	fm.Colors["green"].Println("正在获取最新版本...")
-	
-	var apiURL string
-	if source == 1 {
-		apiURL = GiteeLatestAPI
-	} else {
-		apiURL = GithubLatestAPI
-	}
 
-	resp, err := http.Get(apiURL)
+	resp, err := httpGet(ctx, mirror.latestAPI(), nil)
 	if err != nil {
 		return err
 	}
@@ -164,6 +253,9 @@ func (fm *FrpsManager) getLatestVersion(source int) error {
 	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
 		return err
 	}
+	if release.TagName == "" {
+		return fmt.Errorf("%s 没有返回版本号", mirror.latestAPI())
+	}
 
 	// 移除版本号前的 'v'
 	fm.SystemInfo.FrpsVersion = strings.TrimPrefix(release.TagName, "v")
@@ -173,55 +265,108 @@ func (fm *FrpsManager) getLatestVersion(source int) error {
 }
 
 // getServerIP 获取服务器公网IP
-func (fm *FrpsManager) getServerIP() string {
+func (fm *FrpsManager) getServerIP(ctx context.Context) string {
 	fm.Colors["green"].Println("正在获取服务器IP...")
 	
-	resp, err := http.Get("https://api.ipify.org")
+	ip, err := publicIP(ctx)
 	if err != nil {
 		fm.Colors["yellow"].Println("获取IP失败，使用默认值")
 		return "127.0.0.1"
 	}
-	defer resp.Body.Close()
+	return ip
+}
 
-	body, err := io.ReadAll(resp.Body)
+// publicIP 通过 ipify 查询本机公网 IP
+func publicIP(ctx context.Context) (string, error) {
+	resp, err := httpGet(ctx, "https://api.ipify.org", nil)
 	if err != nil {
-		return "127.0.0.1"
+		return "", err
 	}
+	defer resp.Body.Close()
 
-	return strings.TrimSpace(string(body))
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 256))
+	if err != nil {
+		return "", err
+	}
+	ip := strings.TrimSpace(string(body))
+	if net.ParseIP(ip) == nil {
+		return "", fmt.Errorf("无效的 IP 地址: %q", ip)
+	}
+	return ip, nil
 }
 
-// collectUserConfig 收集用户配置
-func (fm *FrpsManager) collectUserConfig(serverIP string) error {
+// collectUserConfig 收集用户配置，present 中的字段已预先设置，不再询问
+func (fm *FrpsManager) collectUserConfig(serverIP string, present map[string]bool) error {
 	fmt.Println()
 	fm.Colors["red"].Println("————————————————————————————————————————————")
 	fm.Colors["red"].Println("     请输入您的服务器设置:")
 	fm.Colors["red"].Println("————————————————————————————————————————————")
 
+	if len(present) > 0 {
+		var keys []string
+		for _, key := range configKeys() {
+			if present[key] {
+				keys = append(keys, key)
+			}
+		}
+		fm.Colors["yellow"].Printf("以下配置已预先设置，将不再询问: %s\n", strings.Join(keys, ", "))
+	}
+
 	// 收集各项配置
-	fm.Config.BindPort = fm.inputPort("bind_port", 5443)
-	fm.Config.VhostHTTPPort = fm.inputPort("vhost_http_port", 80)
-	fm.Config.VhostHTTPSPort = fm.inputPort("vhost_https_port", 443)
-	fm.Config.DashboardPort = fm.inputPort("dashboard_port", 6443)
+	if !present["bind_port"] {
+		fm.Config.BindPort = fm.inputPort("bind_port", DefaultBindPort)
+	}
+	if !present["vhost_http_port"] {
+		fm.Config.VhostHTTPPort = fm.inputPort("vhost_http_port", DefaultVhostHTTPPort)
+	}
+	if !present["vhost_https_port"] {
+		fm.Config.VhostHTTPSPort = fm.inputPort("vhost_https_port", DefaultVhostHTTPSPort)
+	}
+	if !present["dashboard_port"] {
+		fm.Config.DashboardPort = fm.inputPort("dashboard_port", DefaultDashboardPort)
+	}
 	
-	fm.Config.DashboardUser = fm.inputString("dashboard_user", "admin")
-	fm.Config.DashboardPwd = fm.inputString("dashboard_pwd", fm.generateRandomString(8))
-	fm.Config.Token = fm.inputString("token", fm.generateRandomString(16))
-	fm.Config.SubdomainHost = fm.inputString("subdomain_host", serverIP)
+	if !present["dashboard_user"] {
+		fm.Config.DashboardUser = fm.inputString("dashboard_user", DefaultDashboardUser)
+	}
+	if !present["dashboard_pwd"] {
+		fm.Config.DashboardPwd = fm.inputString("dashboard_pwd", fm.generateRandomString(8))
+	}
+	if !present["token"] {
+		fm.Config.Token = fm.inputString("token", fm.generateRandomString(16))
+	}
+	if !present["subdomain_host"] {
+		fm.Config.SubdomainHost = fm.inputString("subdomain_host", serverIP)
+	}
 	
-	fm.Config.MaxPoolCount = fm.inputNumber("max_pool_count", 5, 50)
-	fm.Config.LogLevel = fm.selectLogLevel()
-	fm.Config.LogMaxDays = fm.inputNumber("log_max_days", 3, 15)
+	if !present["max_pool_count"] {
+		fm.Config.MaxPoolCount = fm.inputNumber("max_pool_count", DefaultMaxPoolCount, 50)
+	}
+	if !present["log_level"] {
+		fm.Config.LogLevel = fm.selectLogLevel()
+	}
+	if !present["log_max_days"] {
+		fm.Config.LogMaxDays = fm.inputNumber("log_max_days", DefaultLogMaxDays, 15)
+	}
 	
-	fm.Config.LogFile = fm.selectLogFile()
-	fm.Config.TCPMux = fm.selectBoolOption("tcp_mux", true)
+	if !present["log_file"] {
+		fm.Config.LogFile = fm.selectLogFile()
+	}
+	if !present["tcp_mux"] {
+		fm.Config.TCPMux = fm.selectBoolOption("tcp_mux", true)
+	}
 	
-	transportProtocol := fm.selectBoolOption("transport protocol support", true)
-	fm.Config.TransportProtocol = transportProtocol
+	if !present["transport_protocol"] {
+		fm.Config.TransportProtocol = fm.selectBoolOption("transport protocol support", true)
+	}
 	
-	if transportProtocol {
-		fm.Config.KCPBindPort = fm.inputPort("kcp_bind_port", fm.Config.BindPort)
-		fm.Config.QuicBindPort = fm.inputPort("quic_bind_port", fm.Config.VhostHTTPSPort)
+	if fm.Config.TransportProtocol {
+		if !present["kcp_bind_port"] {
+			fm.Config.KCPBindPort = fm.inputPort("kcp_bind_port", fm.Config.BindPort)
+		}
+		if !present["quic_bind_port"] {
+			fm.Config.QuicBindPort = fm.inputPort("quic_bind_port", fm.Config.VhostHTTPSPort)
+		}
 	}
 
 	return nil
@@ -256,9 +401,17 @@ func (fm *FrpsManager) inputPort(name string, defaultValue int) int {
 
 // checkPort 检查端口是否被占用
 func (fm *FrpsManager) checkPort(port int) bool {
+	if !isPortAvailable(port) {
+		fm.Colors["red"].Printf("错误：端口 %d 已被占用\n", port)
+		return false
+	}
+	return true
+}
+
+// isPortAvailable 检查 TCP 端口是否可以监听
+func isPortAvailable(port int) bool {
 	ln, err := net.Listen("tcp", fmt.Sprintf(":%d", port))
 	if err != nil {
-		fm.Colors["red"].Printf("错误：端口 %d 已被占用\n", port)
 		return false
 	}
 	ln.Close()
@@ -345,7 +498,7 @@ func (fm *FrpsManager) selectLogFile() string {
 		return "/dev/null"
 	}
 	// 使用绝对路径而不是相对路径，确保日志文件在正确的目录
-	return filepath.Join(ProgramDir, "frps.log")
+	return fm.Component.LogPath()
 }
 
 // selectBoolOption 选择布尔选项
@@ -409,35 +562,30 @@ func (fm *FrpsManager) showConfigConfirmation(serverIP string) {
 	fmt.Println()
 }
 
-// performInstall 执行安装
-func (fm *FrpsManager) performInstall(downloadSource int) error {
+// performInstall 执行安装，writeConfig 生成当前组件的配置文件
+func (fm *FrpsManager) performInstall(ctx context.Context, mirror *Mirror, opts *DownloadOptions, writeConfig func() error) error {
 	// 创建程序目录
-	if err := os.MkdirAll(ProgramDir, 0755); err != nil {
+	if err := os.MkdirAll(fm.Component.Dir, 0755); err != nil {
 		return fmt.Errorf("创建程序目录失败: %v", err)
 	}
 
 	// 切换到程序目录
-	if err := os.Chdir(ProgramDir); err != nil {
+	if err := os.Chdir(fm.Component.Dir); err != nil {
 		return fmt.Errorf("切换目录失败: %v", err)
 	}
 
 	// 生成配置文件
-	if err := fm.generateConfigFile(); err != nil {
+	if err := writeConfig(); err != nil {
 		return fmt.Errorf("生成配置文件失败: %v", err)
 	}
 
-	// 下载并安装 frps 二进制文件
-	if err := fm.downloadAndInstallBinary(downloadSource); err != nil {
+	// 下载并安装二进制文件
+	if err := fm.downloadAndInstallBinary(ctx, mirror, opts); err != nil {
 		return fmt.Errorf("下载安装二进制文件失败: %v", err)
 	}
 
-	// 下载并安装初始化脚本
-	if err := fm.downloadInitScript(); err != nil {
-		return fmt.Errorf("下载初始化脚本失败: %v", err)
-	}
-
 	// 设置服务开机启动
-	if err := fm.setupService(); err != nil {
+	if err := fm.setupService(ctx); err != nil {
 		return fmt.Errorf("设置服务失败: %v", err)
 	}
 
@@ -449,114 +597,101 @@ func (fm *FrpsManager) performInstall(downloadSource int) error {
 	return nil
 }
 
-// downloadAndInstallBinary 下载并安装二进制文件
-func (fm *FrpsManager) downloadAndInstallBinary(downloadSource int) error {
-	// 检查本地是否已有frps二进制文件（并且不是空文件）
-	binaryPath := filepath.Join(ProgramDir, "frps")
-	if stat, err := os.Stat(binaryPath); err == nil && stat.Size() > 0 {
-		fm.Colors["yellow"].Println("检测到本地已有 frps 二进制文件，跳过下载...")
-		
-		// 设置权限确保可执行
-		if err := os.Chmod(binaryPath, 0755); err != nil {
-			fm.Colors["yellow"].Printf("设置权限失败: %v\n", err)
-		}
-		return nil
-	}
-
-	var baseURL string
-	if downloadSource == 1 {
-		baseURL = GiteeDownloadURL
-	} else {
-		baseURL = GithubDownloadURL
-	}
-
+// downloadAndInstallBinary 下载并安装 SystemInfo.FrpsVersion 版本的二进制文件，替换已有的文件。
+// 重新安装、修复或回退时优先使用缓存的发布包
+func (fm *FrpsManager) downloadAndInstallBinary(ctx context.Context, mirror *Mirror, opts *DownloadOptions) error {
 	filename := fmt.Sprintf("frp_%s_linux_%s.tar.gz", fm.SystemInfo.FrpsVersion, fm.SystemInfo.FrpsArch)
-	downloadURL := fmt.Sprintf("%s/v%s/%s", baseURL, fm.SystemInfo.FrpsVersion, filename)
-
-	fm.Colors["green"].Printf("正在下载 %s...\n", filename)
-	
-	// 使用带进度条的下载
-	if err := fm.downloadWithProgress(downloadURL, filename, "下载 frps 二进制文件"); err != nil {
-		return err
+	archiveName := filename
+	if opts.FromArchive != "" {
+		archiveName = filepath.Base(opts.FromArchive)
 	}
 
-	fm.Colors["green"].Println("正在解压...")
-	
-	// 解压文件
-	if err := fm.extractTarGz(filename, "."); err != nil {
-		return err
+	// 在线安装时先按版本与架构查找缓存，命中时不再获取校验文件；未命中时确定期望的校验值后再按校验值查找
+	cacheDir := fm.prepareCacheDir(opts)
+	archive, expected := "", ""
+	var err error
+	if cacheDir != "" && opts.FromArchive == "" && !opts.SkipVerify {
+		archive, expected = fm.findCachedArchive(cacheDir, fm.SystemInfo.FrpsVersion, fm.SystemInfo.FrpsArch, opts.SHA256, archiveName)
 	}
-
-	// 移动二进制文件
-	extractedDir := fmt.Sprintf("frp_%s_linux_%s", fm.SystemInfo.FrpsVersion, fm.SystemInfo.FrpsArch)
-	srcPath := filepath.Join(extractedDir, "frps")
-	dstPath := filepath.Join(ProgramDir, "frps")
-	
-	if err := os.Rename(srcPath, dstPath); err != nil {
-		return err
-	}
-
-	// 设置权限
-	if err := os.Chmod(dstPath, 0755); err != nil {
-		return err
-	}
-
-	// 清理临时文件
-	os.Remove(filename)
-	os.RemoveAll(extractedDir)
-
-	return nil
-}
-
-// extractTarGz 解压 tar.gz 文件
-func (fm *FrpsManager) extractTarGz(filename, destDir string) error {
-	file, err := os.Open(filename)
-	if err != nil {
-		return err
+	if archive == "" {
+		if expected, err = fm.expectedChecksum(ctx, archiveName, mirror, opts); err != nil {
+			return err
+		}
+		if cacheDir != "" {
+			archive = fm.lookupCache(cacheDir, fm.SystemInfo.FrpsVersion, fm.SystemInfo.FrpsArch, expected, archiveName)
+		}
 	}
-	defer file.Close()
-
-	gzr, err := gzip.NewReader(file)
-	if err != nil {
-		return err
+	if archive != "" {
+		fm.Colors["green"].Printf("使用缓存的发布包 %s\n", archive)
 	}
-	defer gzr.Close()
+	// 缓存中的发布包查找时已经校验
+	cached := archive != ""
 
-	tr := tar.NewReader(gzr)
+	members := releaseMembers(fm.Component)
+	description := fmt.Sprintf("下载 %s 二进制文件", fm.Component.Name)
+	var files *extraction
 
-	for {
-		header, err := tr.Next()
-		if err == io.EOF {
-			break
-		}
-		if err != nil {
-			return err
-		}
+	// temporary 表示 archive 是本次下载的临时文件，安装后删除
+	temporary := false
+	if archive == "" && opts.FromArchive == "" {
+		downloadURL := mirror.URL(fm.SystemInfo.FrpsVersion, fm.SystemInfo.FrpsArch, filename)
 
-		target := filepath.Join(destDir, header.Name)
+		fm.Colors["green"].Printf("正在从 %s 下载 %s...\n", mirror.Name, filename)
 
-		switch header.Typeflag {
-		case tar.TypeDir:
-			if err := os.MkdirAll(target, os.FileMode(header.Mode)); err != nil {
+		if cacheDir == "" {
+			// 没有缓存目录时边下载边解压，校验通过后才安装
+			files, err = fm.downloadAndExtract(ctx, downloadURL, description, members)
+			if err != nil {
 				return err
 			}
-		case tar.TypeReg:
-			if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			if err := fm.checkChecksum(files.checksum, expected); err != nil {
+				files.Cleanup()
 				return err
 			}
-			
-			file, err := os.OpenFile(target, os.O_CREATE|os.O_RDWR, os.FileMode(header.Mode))
-			if err != nil {
+		} else {
+			// 下载到缓存目录，中断后下次可以继续
+			archive = filepath.Join(cacheDir, cacheDownloadsDir, filename)
+			if err := fm.downloadWithProgress(ctx, downloadURL, archive, description); err != nil {
 				return err
 			}
-			
-			if _, err := io.Copy(file, tr); err != nil {
-				file.Close()
+			temporary = true
+		}
+	} else if archive == "" {
+		archive = opts.FromArchive
+	}
+
+	if files == nil {
+		if !cached {
+			// 解压前校验发布包，校验失败时删除下载的文件并终止安装
+			actual, err := fm.verifyArchive(archive, expected)
+			if err != nil {
+				if temporary {
+					os.Remove(archive)
+				}
 				return err
 			}
-			file.Close()
+
+			if cacheDir != "" {
+				cached, err := fm.storeCache(cacheDir, fm.SystemInfo.FrpsVersion, fm.SystemInfo.FrpsArch, actual, archive, temporary)
+				if err != nil {
+					fm.Colors["yellow"].Printf("写入缓存失败: %v\n", err)
+				} else {
+					archive, temporary = cached, false
+				}
+			}
+		}
+
+		fm.Colors["green"].Println("正在解压...")
+		files, err = extractArchive(archive, members)
+		if temporary {
+			os.Remove(archive)
+		}
+		if err != nil {
+			return err
 		}
 	}
+	defer files.Cleanup()
 
-	return nil
-} 
\ No newline at end of file
+	// 解压的文件在校验通过后才移动到安装目录
+	return files.Commit()
+}
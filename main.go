@@ -1,29 +1,36 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"os"
 	"runtime"
-	"strings"
 
 	"github.com/fatih/color"
 )
 
 const (
-	ProgramName    = "frps"
 	Version        = "1.0.8"
-	ProgramDir     = "/usr/local/frps"
-	ConfigFile     = "frps.toml"
-	InitScript     = "/etc/init.d/frps"
-	InitScriptURL  = "https://raw.githubusercontent.com/mvscode/frps-onekey/master/frps.init"
 	
 	GiteeDownloadURL    = "https://gitee.com/mvscode/frps-onekey/releases/download"
 	GithubDownloadURL   = "https://github.com/fatedier/frp/releases/download" 
-	GiteeLatestAPI      = "https://gitee.com/api/v5/repos/mvscode/frps-onekey/releases/latest"
-	GithubLatestAPI     = "https://api.github.com/repos/fatedier/frp/releases/latest"
+	GiteeReleasesAPI    = "https://gitee.com/api/v5/repos/mvscode/frps-onekey/releases"
+	GithubReleasesAPI   = "https://api.github.com/repos/fatedier/frp/releases"
 	UpdateCheckURL      = "https://raw.githubusercontent.com/mvscode/frps-onekey/master/install-frps.sh"
 )
 
+// 交互式安装的默认值，无人值守安装未提供对应字段时同样使用
+const (
+	DefaultBindPort       = 5443
+	DefaultVhostHTTPPort  = 80
+	DefaultVhostHTTPSPort = 443
+	DefaultDashboardPort  = 6443
+	DefaultDashboardUser  = "admin"
+	DefaultMaxPoolCount   = 5
+	DefaultLogLevel       = "info"
+	DefaultLogMaxDays     = 3
+)
+
 // Config 存储配置信息
 type Config struct {
 	BindPort         int    `json:"bind_port"`
@@ -42,12 +49,19 @@ type Config struct {
 	KCPBindPort      int    `json:"kcp_bind_port"`
 	QuicBindPort     int    `json:"quic_bind_port"`
 	TransportProtocol bool  `json:"transport_protocol"`
+	SecretsEnv       bool   `json:"secrets_env"` // token 与 Dashboard 密码保存在环境变量文件中，配置文件通过模板引用
+
+	// Extra frps.toml 中 Config 未建模的配置项，键为点分隔的完整键名，重新生成配置时原样写回
+	Extra map[string]interface{} `json:"-"`
 }
 
 // SystemInfo 系统信息
 type SystemInfo struct {
-	OS          string
-	Version     string
+	OS          string   // 发行版名称 (os-release NAME)
+	ID          string   // 发行版 ID (os-release ID)
+	IDLike      []string // 上游发行版 (os-release ID_LIKE)
+	Family      string   // 发行版家族，见 Family* 常量
+	Version     string   // 发行版版本 (os-release VERSION_ID)
 	Arch        string
 	Is64Bit     bool
 	FrpsArch    string
@@ -56,14 +70,24 @@ type SystemInfo struct {
 
 // Release GitHub/Gitee API 响应结构
 type Release struct {
-	TagName string `json:"tag_name"`
+	TagName     string         `json:"tag_name"`
+	Name        string         `json:"name"`
+	Prerelease  bool           `json:"prerelease"`
+	Draft       bool           `json:"draft"`
+	PublishedAt string         `json:"published_at"`
+	CreatedAt   string         `json:"created_at"`
+	Assets      []ReleaseAsset `json:"assets"`
 }
 
 // FrpsManager 主管理器
 type FrpsManager struct {
-	Config     *Config
-	SystemInfo *SystemInfo
-	Colors     map[string]*color.Color
+	Config         *Config
+	SystemInfo     *SystemInfo
+	Colors         map[string]*color.Color
+	Component      *Component     // 当前管理的组件，默认为 frps
+	Service        ServiceManager // 当前初始化系统对应的服务管理后端
+	NonInteractive bool           // 非交互模式，不读取标准输入
+	Offline        bool           // 离线安装，不访问网络
 }
 
 func main() {
@@ -73,24 +97,97 @@ func main() {
 	}
 
 	manager := NewFrpsManager()
+	// 旧版本写入的配置文件对所有用户可读，每次运行时修正
+	manager.fixSecretPermissions()
+	ctx, stop := interruptContext()
+	defer func() {
+		// Ctrl+C 取消后以 130 退出，与 shell 的约定一致
+		interrupted := ctx.Err() != nil
+		stop()
+		if interrupted {
+			os.Exit(130)
+		}
+	}()
 	
 	switch os.Args[1] {
 	case "install":
-		manager.Install()
+		opts, err := parseInstallArgs(os.Args[2:], &manager.NonInteractive)
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		manager.Install(ctx, opts)
 	case "uninstall":
+		if _, err := parseYesArgs("uninstall", "uninstall [--yes]", os.Args[2:], &manager.NonInteractive); err != nil {
+			printArgsError(err)
+			return
+		}
 		manager.Uninstall()
 	case "update":
-		manager.Update()
+		opts, err := parseUpdateArgs("update", os.Args[2:], &manager.NonInteractive)
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		manager.Update(ctx, opts)
+	case "versions":
+		opts, err := parseVersionsArgs(os.Args[2:])
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		manager.ListVersions(ctx, opts)
+	case "cache":
+		action, opts, err := parseCacheArgs(os.Args[2:])
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		if action == "prune" {
+			manager.CachePrune(opts)
+		} else {
+			manager.CacheList(opts)
+		}
+	case "client":
+		runClient(ctx, manager, os.Args[2:])
+	case "client-config":
+		opts, err := parseClientConfigArgs(os.Args[2:])
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		manager.GenerateClientConfig(ctx, opts)
 	case "config":
-		manager.ConfigEdit()
+		action, params, err := parseConfigArgs("config", os.Args[2:], &manager.NonInteractive)
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		runConfigAction(manager, action, params)
 	case "import-config":
-		if len(os.Args) < 3 {
+		opts, err := parseImportArgs(os.Args[2:], &manager.NonInteractive)
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		if opts.Path == "" {
 			fmt.Println("错误：请指定配置文件路径")
-			fmt.Println("使用方法: frps-onekey import-config <配置文件路径>")
+			fmt.Println("使用方法: frps-onekey import-config [--yes] [--secrets-env] <配置文件路径>")
 			fmt.Println("示例: frps-onekey import-config /path/to/your/frps.toml")
 			return
 		}
-		manager.ImportConfig(os.Args[2])
+		manager.ImportConfig(opts)
+	case "secrets":
+		action, name, value, err := parseSecretsArgs(os.Args[2:], &manager.NonInteractive)
+		if err != nil {
+			printArgsError(err)
+			return
+		}
+		if action == "set" {
+			manager.SecretsSet(name, value)
+		} else {
+			manager.SecretsShow()
+		}
 	case "start":
 		manager.Start()
 	case "stop":
@@ -106,11 +203,39 @@ func main() {
 	}
 }
 
+// runConfigAction 执行 config 与 client config 的操作，params 为 parseConfigArgs 返回的位置参数
+func runConfigAction(fm *FrpsManager, action string, params []string) {
+	switch action {
+	case "get":
+		fm.ConfigGet(params[0])
+	case "set":
+		fm.ConfigSet(params[0], params[1])
+	case "unset":
+		fm.ConfigUnset(params[0])
+	case "history":
+		fm.ConfigHistory()
+	case "diff":
+		fm.ConfigDiff(params[0], params[1])
+	case "rollback":
+		fm.ConfigRollback(params[0])
+	default:
+		fm.ConfigEdit()
+	}
+}
+
+// printArgsError 输出参数解析错误，--help 时不重复输出
+func printArgsError(err error) {
+	if err != flag.ErrHelp {
+		fmt.Printf("错误：%v\n", err)
+	}
+}
+
 // NewFrpsManager 创建新的管理器实例
 func NewFrpsManager() *FrpsManager {
 	manager := &FrpsManager{
 		Config:     &Config{},
 		SystemInfo: &SystemInfo{},
+		Component:  ServerComponent,
 		Colors: map[string]*color.Color{
 			"red":    color.New(color.FgRed, color.Bold),
 			"green":  color.New(color.FgGreen, color.Bold),
@@ -121,6 +246,7 @@ func NewFrpsManager() *FrpsManager {
 	}
 	
 	manager.detectSystemInfo()
+	manager.Service = detectServiceManager(manager)
 	return manager
 }
 
@@ -139,22 +265,7 @@ func (fm *FrpsManager) detectSystemInfo() {
 	fm.SystemInfo.Arch = runtime.GOARCH
 	
 	// 检测操作系统
-	if content, err := os.ReadFile("/etc/os-release"); err == nil {
-		contentStr := string(content)
-		if strings.Contains(contentStr, "CentOS") {
-			fm.SystemInfo.OS = "CentOS"
-		} else if strings.Contains(contentStr, "Ubuntu") {
-			fm.SystemInfo.OS = "Ubuntu"
-		} else if strings.Contains(contentStr, "Debian") {
-			fm.SystemInfo.OS = "Debian"
-		} else if strings.Contains(contentStr, "Red Hat") {
-			fm.SystemInfo.OS = "RHEL"
-		} else if strings.Contains(contentStr, "Rocky") {
-			fm.SystemInfo.OS = "Rocky"
-		} else if strings.Contains(contentStr, "AlmaLinux") {
-			fm.SystemInfo.OS = "AlmaLinux"
-		}
-	}
+	fm.SystemInfo.detectDistro()
 	
 	// 设置架构信息
 	switch runtime.GOARCH {
@@ -202,22 +313,39 @@ func (fm *FrpsManager) checkRoot() bool {
 // showUsage 显示使用说明
 func showUsage() {
 	fmt.Println("frps 管理工具")
-	fmt.Println("使用方法: frps-onekey {install|uninstall|update|config|import-config|start|stop|restart|status|version}")
+	fmt.Println("使用方法: frps-onekey {install|uninstall|update|versions|cache|client|client-config|config|import-config|secrets|start|stop|restart|status|version}")
 	fmt.Println()
 	fmt.Println("命令说明:")
-	fmt.Println("  install        - 安装 frps")
+	fmt.Println("  install        - 安装 frps (--answers <文件> 无人值守安装，--help 查看全部参数)")
 	fmt.Println("  uninstall      - 卸载 frps")
-	fmt.Println("  update         - 更新 frps")
-	fmt.Println("  config         - 编辑配置文件")
-	fmt.Println("  import-config  - 导入自定义配置文件")
+	fmt.Println("  update         - 更新 frps (--version <版本号> 切换到指定版本)")
+	fmt.Println("  versions       - 列出可用版本 (--page、--per-page 分页，--prerelease 包含预发布版本)")
+	fmt.Println("  cache          - 管理发布包缓存 (list 列出，prune --max-size/--max-age 清理)")
+	fmt.Println("  client         - 管理 frpc 客户端 (install|update|start|stop|status|config，详见 client 子命令)")
+	fmt.Println("  client-config  - 生成与本机 frps 匹配的 frpc 配置 (--output <文件|->、--qrcode、--protocol kcp|quic)")
+	fmt.Println("  config         - 编辑配置文件 (get|set|unset 修改单个配置项，history|diff|rollback 管理历史版本)")
+	fmt.Println("  import-config  - 导入自定义配置文件 (--secrets-env 将 token 与 Dashboard 密码移到环境变量文件)")
+	fmt.Println("  secrets        - 管理环境变量文件中的 token 与 Dashboard 密码 (show|set)")
 	fmt.Println("  start          - 启动 frps 服务")
 	fmt.Println("  stop           - 停止 frps 服务")
 	fmt.Println("  restart        - 重启 frps 服务")
 	fmt.Println("  status         - 查看 frps 状态")
 	fmt.Println("  version        - 显示版本信息")
 	fmt.Println()
+	fmt.Println("install/update/uninstall/import-config/config set 等命令支持 --yes 非交互模式，")
+	fmt.Println("命令行参数也可通过 FRPS_ONEKEY_<参数名> 环境变量设置，例如 FRPS_ONEKEY_BIND_PORT=7000")
+	fmt.Println()
 	fmt.Println("示例:")
 	fmt.Println("  frps-onekey install")
+	fmt.Println("  frps-onekey install --answers /path/to/answers.json")
+	fmt.Println("  frps-onekey install --bind-port 7000 --token mytoken --download-source github --yes")
+	fmt.Println("  frps-onekey install --version 0.61.1")
+	fmt.Println("  frps-onekey cache prune --max-size 200M --max-age 30d")
+	fmt.Println("  frps-onekey client install --server-addr 1.2.3.4 --token mytoken --yes")
+	fmt.Println("  frps-onekey client-config --protocol kcp --qrcode")
 	fmt.Println("  frps-onekey import-config /path/to/frps.toml")
 	fmt.Println("  frps-onekey config")
+	fmt.Println("  frps-onekey config set webServer.port 7501")
+	fmt.Println("  frps-onekey config rollback 3")
+	fmt.Println("  frps-onekey secrets set auth.token")
 } 
\ No newline at end of file
@@ -0,0 +1,173 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MirrorsFiles 默认的下载源配置文件，按顺序使用第一个存在的文件
+var MirrorsFiles = []string{
+	"/etc/frps-onekey/mirrors.toml",
+	"/etc/frps-onekey/mirrors.yaml",
+	"/etc/frps-onekey/mirrors.yml",
+	"/etc/frps-onekey/mirrors.json",
+}
+
+// Mirror 下载源，发布包地址为 URL 模板，支持 {version}、{arch}、{filename} 占位符
+type Mirror struct {
+	Name        string `json:"name"`
+	Priority    int    `json:"priority"`     // 优先级，数字越小越优先，非交互模式使用优先级最高的下载源
+	DownloadURL string `json:"download_url"` // 发布包地址模板，校验文件使用同一模板
+	ReleasesAPI string `json:"releases_api"` // GitHub/Gitee 兼容的版本列表接口，为空时使用 GitHub 官方接口
+}
+
+// builtinMirrors 内置下载源
+var builtinMirrors = []Mirror{
+	{
+		Name:        "github",
+		Priority:    10,
+		DownloadURL: GithubDownloadURL + "/v{version}/{filename}",
+		ReleasesAPI: GithubReleasesAPI,
+	},
+	{
+		Name:        "gitee",
+		Priority:    20,
+		DownloadURL: GiteeDownloadURL + "/v{version}/{filename}",
+		ReleasesAPI: GiteeReleasesAPI,
+	},
+}
+
+// URL 展开发布包地址模板
+func (m *Mirror) URL(version, arch, filename string) string {
+	return strings.NewReplacer(
+		"{version}", version,
+		"{arch}", arch,
+		"{filename}", filename,
+	).Replace(m.DownloadURL)
+}
+
+// releasesAPI 返回版本列表接口
+func (m *Mirror) releasesAPI() string {
+	if m.ReleasesAPI == "" {
+		return GithubReleasesAPI
+	}
+	return strings.TrimRight(m.ReleasesAPI, "/")
+}
+
+// latestAPI 返回最新版本接口
+func (m *Mirror) latestAPI() string {
+	return m.releasesAPI() + "/latest"
+}
+
+// isGitee Gitee 接口的分页与排序参数与 GitHub 不同
+func (m *Mirror) isGitee() bool {
+	return strings.Contains(m.releasesAPI(), "gitee.com")
+}
+
+// validate 检查下载源配置
+func (m *Mirror) validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("缺少 name")
+	}
+	if !strings.Contains(m.DownloadURL, "{filename}") {
+		return fmt.Errorf("下载源 %s 的 download_url 必须包含 {filename} 占位符", m.Name)
+	}
+	for _, raw := range []string{m.URL("0.0.0", "amd64", ChecksumFileName), m.ReleasesAPI} {
+		if raw == "" {
+			continue
+		}
+		u, err := url.Parse(raw)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("下载源 %s 的地址无效: %s", m.Name, raw)
+		}
+	}
+	return nil
+}
+
+// loadMirrorsFile 读取下载源配置文件，格式为 mirrors 数组
+func loadMirrorsFile(path string) ([]Mirror, error) {
+	raw, err := decodeStructuredFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, err
+	}
+	var file struct {
+		Mirrors []Mirror `json:"mirrors"`
+	}
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("解析文件失败: %v", err)
+	}
+
+	for i := range file.Mirrors {
+		if err := file.Mirrors[i].validate(); err != nil {
+			return nil, fmt.Errorf("第 %d 个下载源: %v", i+1, err)
+		}
+	}
+	return file.Mirrors, nil
+}
+
+// loadMirrors 合并内置下载源、配置文件与 --mirror 参数，同名下载源以后者为准，按优先级排序
+func loadMirrors(opts *DownloadOptions) ([]*Mirror, error) {
+	mirrors := append([]Mirror{}, builtinMirrors...)
+
+	path := opts.MirrorsFile
+	if path == "" {
+		for _, file := range MirrorsFiles {
+			if fileExists(file) {
+				path = file
+				break
+			}
+		}
+	}
+	if path != "" {
+		custom, err := loadMirrorsFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("读取下载源配置 %s 失败: %v", path, err)
+		}
+		mirrors = append(mirrors, custom...)
+	}
+
+	// --mirror 指定的下载源优先级最高
+	for i, tmpl := range opts.Mirrors {
+		m := Mirror{Name: "mirror" + strconv.Itoa(i+1), Priority: i - len(opts.Mirrors), DownloadURL: tmpl}
+		if err := m.validate(); err != nil {
+			return nil, err
+		}
+		mirrors = append(mirrors, m)
+	}
+
+	index := make(map[string]int)
+	var result []*Mirror
+	for i := range mirrors {
+		m := mirrors[i]
+		if n, ok := index[m.Name]; ok {
+			result[n] = &m
+			continue
+		}
+		index[m.Name] = len(result)
+		result = append(result, &m)
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].Priority < result[j].Priority })
+	return result, nil
+}
+
+// findMirror 按名称查找下载源
+func findMirror(mirrors []*Mirror, name string) (*Mirror, error) {
+	names := make([]string, 0, len(mirrors))
+	for _, m := range mirrors {
+		if strings.EqualFold(m.Name, name) {
+			return m, nil
+		}
+		names = append(names, m.Name)
+	}
+	return nil, fmt.Errorf("未知的下载源: %s (可选: %s)", name, strings.Join(names, ", "))
+}
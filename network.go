@@ -0,0 +1,281 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/fatih/color"
+)
+
+// 网络请求的默认参数
+const (
+	DefaultConnectTimeout = 10 * time.Second
+	DefaultReadTimeout    = 30 * time.Second
+	DefaultRetries        = 3
+
+	retryBaseDelay       = time.Second
+	retryMaxDelay        = 16 * time.Second
+	interruptGracePeriod = 3 * time.Second // Ctrl+C 后等待当前操作退出的时间
+)
+
+// NetworkOptions 网络请求的超时与重试设置
+type NetworkOptions struct {
+	ConnectTimeout time.Duration // 建立连接 (含 TLS 握手) 的超时
+	ReadTimeout    time.Duration // 等待响应头以及两次读取之间的最长间隔
+	Retries        int           // 失败后的重试次数
+}
+
+// network 当前生效的网络设置
+var network = NetworkOptions{
+	ConnectTimeout: DefaultConnectTimeout,
+	ReadTimeout:    DefaultReadTimeout,
+	Retries:        DefaultRetries,
+}
+
+// httpClient 所有网络请求共用的客户端，代理设置来自环境变量
+var httpClient = newHTTPClient(network)
+
+// configureNetwork 应用命令行参数中的网络设置
+func configureNetwork(opts NetworkOptions) {
+	network = opts
+	httpClient = newHTTPClient(opts)
+}
+
+// newHTTPClient 创建客户端，读取超时由 httpGet 按每次读取计算，因此不设置整体超时
+func newHTTPClient(opts NetworkOptions) *http.Client {
+	return &http.Client{Transport: newTransport(opts)}
+}
+
+// newTransport 创建支持 HTTP_PROXY、HTTPS_PROXY、ALL_PROXY 与 NO_PROXY 的 Transport，代理可以是 http、https 或 socks5
+func newTransport(opts NetworkOptions) *http.Transport {
+	applyAllProxy()
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+	transport.DialContext = (&net.Dialer{
+		Timeout:   opts.ConnectTimeout,
+		KeepAlive: 30 * time.Second,
+	}).DialContext
+	transport.TLSHandshakeTimeout = opts.ConnectTimeout
+	transport.ResponseHeaderTimeout = opts.ReadTimeout
+	return transport
+}
+
+// applyAllProxy 未设置 HTTP(S)_PROXY 时使用 ALL_PROXY，由标准库统一处理 NO_PROXY
+func applyAllProxy() {
+	all := getenvAny("ALL_PROXY", "all_proxy")
+	if all == "" {
+		return
+	}
+	// 标准库的 socks5 代理本身就在代理端解析域名
+	if strings.HasPrefix(all, "socks5h://") {
+		all = "socks5://" + strings.TrimPrefix(all, "socks5h://")
+	}
+	if getenvAny("HTTP_PROXY", "http_proxy") == "" {
+		os.Setenv("HTTP_PROXY", all)
+	}
+	if getenvAny("HTTPS_PROXY", "https_proxy") == "" {
+		os.Setenv("HTTPS_PROXY", all)
+	}
+}
+
+// getenvAny 返回第一个非空的环境变量
+func getenvAny(names ...string) string {
+	for _, name := range names {
+		if value := os.Getenv(name); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// StatusError 服务器返回了非预期的状态码
+type StatusError struct {
+	URL    string
+	Status string
+	Code   int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("%s 返回 %s", e.URL, e.Status)
+}
+
+// retryable 判断错误是否值得重试：网络错误、超时、5xx 与 429，发布包内容错误不重试
+func retryable(err error) bool {
+	if errors.Is(err, context.Canceled) {
+		return false
+	}
+	var archiveErr *ArchiveError
+	if errors.As(err, &archiveErr) {
+		return false
+	}
+	var statusErr *StatusError
+	if errors.As(err, &statusErr) {
+		return statusErr.Code >= 500 || statusErr.Code == http.StatusTooManyRequests
+	}
+	return true
+}
+
+// retryWait 按指数退避等待第 attempt 次重试，ctx 取消时立即返回
+func retryWait(ctx context.Context, attempt int, err error) error {
+	delay := retryBaseDelay << attempt
+	if delay > retryMaxDelay || delay <= 0 {
+		delay = retryMaxDelay
+	}
+	// 加入随机抖动，避免多个分段同时重试
+	delay += time.Duration(rand.Int63n(int64(delay) / 2))
+
+	color.New(color.FgYellow, color.Bold).Printf("\n请求失败: %v，%s 后重试 (%d/%d)...\n",
+		err, delay.Round(100*time.Millisecond), attempt+1, network.Retries)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// withRetry 执行 fn，失败且可以重试时按指数退避重试
+func withRetry(ctx context.Context, fn func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn()
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if attempt >= network.Retries || !retryable(err) {
+			return err
+		}
+		if err := retryWait(ctx, attempt, err); err != nil {
+			return err
+		}
+	}
+}
+
+// httpGet 发送 GET 请求并检查状态码，accept 为空时只接受 200，失败时按指数退避重试
+func httpGet(ctx context.Context, url string, header http.Header, accept ...int) (*http.Response, error) {
+	if len(accept) == 0 {
+		accept = []int{http.StatusOK}
+	}
+
+	var resp *http.Response
+	err := withRetry(ctx, func() error {
+		r, err := doGet(ctx, url, header)
+		if err != nil {
+			return err
+		}
+		for _, code := range accept {
+			if r.StatusCode == code {
+				resp = r
+				return nil
+			}
+		}
+		r.Body.Close()
+		return &StatusError{URL: url, Status: r.Status, Code: r.StatusCode}
+	})
+	return resp, err
+}
+
+// doGet 发送一次 GET 请求，响应体两次读取间隔超过读取超时时中断请求
+func doGet(ctx context.Context, url string, header http.Header) (*http.Response, error) {
+	reqCtx, cancel := context.WithCancel(ctx)
+	req, err := http.NewRequestWithContext(reqCtx, http.MethodGet, url, nil)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	resp.Body = newIdleTimeoutBody(resp.Body, network.ReadTimeout, cancel)
+	return resp, nil
+}
+
+// idleTimeoutBody 读取停滞超过 timeout 时取消请求
+type idleTimeoutBody struct {
+	io.ReadCloser
+	timeout time.Duration
+	timer   *time.Timer
+	cancel  context.CancelFunc
+
+	mu       sync.Mutex
+	timedOut bool
+}
+
+func newIdleTimeoutBody(body io.ReadCloser, timeout time.Duration, cancel context.CancelFunc) *idleTimeoutBody {
+	b := &idleTimeoutBody{ReadCloser: body, timeout: timeout, cancel: cancel}
+	b.timer = time.AfterFunc(timeout, func() {
+		b.mu.Lock()
+		b.timedOut = true
+		b.mu.Unlock()
+		cancel()
+	})
+	return b
+}
+
+func (b *idleTimeoutBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.mu.Lock()
+	timedOut := b.timedOut
+	b.mu.Unlock()
+	if timedOut {
+		return n, fmt.Errorf("读取超时 (超过 %s 没有收到数据)", b.timeout)
+	}
+	b.timer.Reset(b.timeout)
+	return n, err
+}
+
+func (b *idleTimeoutBody) Close() error {
+	b.timer.Stop()
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// interruptContext 第一次 Ctrl+C 时取消进行中的网络操作，超过等待时间或再次 Ctrl+C 时直接退出
+func interruptContext() (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(context.Background())
+	signals := make(chan os.Signal, 2)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	go func() {
+		select {
+		case <-signals:
+		case <-ctx.Done():
+			return
+		}
+		fmt.Println("\n正在取消...")
+		cancel()
+		select {
+		case <-signals:
+		case <-time.After(interruptGracePeriod):
+		}
+		os.Exit(130)
+	}()
+
+	return ctx, func() {
+		signal.Stop(signals)
+		cancel()
+	}
+}
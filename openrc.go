@@ -0,0 +1,91 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// openrcManager 通过 openrc-run 脚本管理 frps 或 frpc，适用于 Alpine、Gentoo 等系统
+type openrcManager struct {
+	fm *FrpsManager
+}
+
+func (o *openrcManager) Name() string {
+	return "openrc"
+}
+
+func (o *openrcManager) Installed() bool {
+	return fileExists(o.fm.Component.InitScript())
+}
+
+// scriptContent 生成 openrc-run 服务脚本，存在环境变量文件时通过 sh 加载
+func (o *openrcManager) scriptContent() string {
+	c := o.fm.Component
+	command, args := c.BinaryPath(), "-c "+c.ConfigPath()
+	if fileExists(c.EnvFile()) {
+		command, args = "/bin/sh", fmt.Sprintf("-c 'set -a; . %s; exec %s'", c.EnvFile(), c.CommandLine())
+	}
+	return fmt.Sprintf(`#!/sbin/openrc-run
+
+name="%s"
+description="%s"
+command="%s"
+command_args="%s"
+command_background=true
+pidfile="/run/${RC_SVCNAME}.pid"
+directory="%s"
+
+depend() {
+	need net
+	after firewall
+}
+`, c.Name, c.Description, command, args, c.Dir)
+}
+
+func (o *openrcManager) Install(ctx context.Context) error {
+	o.fm.Colors["green"].Printf("正在写入 OpenRC 服务脚本 %s...\n", o.fm.Component.InitScript())
+	return writeServiceFile(o.fm.Component.InitScript(), o.scriptContent(), 0755)
+}
+
+func (o *openrcManager) Enable() error {
+	_, err := runCommand("rc-update", "add", o.fm.Component.Name, "default")
+	return err
+}
+
+func (o *openrcManager) Start() error {
+	_, err := runCommand("rc-service", o.fm.Component.Name, "start")
+	return err
+}
+
+func (o *openrcManager) Stop() error {
+	_, err := runCommand("rc-service", o.fm.Component.Name, "stop")
+	return err
+}
+
+func (o *openrcManager) Restart() error {
+	_, err := runCommand("rc-service", o.fm.Component.Name, "restart")
+	return err
+}
+
+func (o *openrcManager) Status() (string, error) {
+	output, err := exec.Command("rc-service", o.fm.Component.Name, "status").CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+func (o *openrcManager) Remove() error {
+	if o.Installed() {
+		runCommand("rc-service", o.fm.Component.Name, "stop")
+		runCommand("rc-update", "del", o.fm.Component.Name, "default")
+	}
+	if err := os.Remove(o.fm.Component.InitScript()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除 %s 失败: %v", o.fm.Component.InitScript(), err)
+	}
+	return nil
+}
+
+func (o *openrcManager) ControlCommand(action string) string {
+	return fmt.Sprintf("rc-service %s %s", o.fm.Component.Name, action)
+}
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// 发行版家族，同一家族使用相同的包管理方式与系统约定
+const (
+	FamilyRHEL   = "rhel"
+	FamilyDebian = "debian"
+	FamilySUSE   = "suse"
+	FamilyArch   = "arch"
+	FamilyAlpine = "alpine"
+)
+
+// distroFamilies 发行版 ID 与家族的对应关系，ID 与 ID_LIKE 均使用此表
+var distroFamilies = map[string]string{
+	"rhel":        FamilyRHEL,
+	"centos":      FamilyRHEL,
+	"fedora":      FamilyRHEL,
+	"rocky":       FamilyRHEL,
+	"almalinux":   FamilyRHEL,
+	"ol":          FamilyRHEL,
+	"amzn":        FamilyRHEL,
+	"openeuler":   FamilyRHEL,
+	"euleros":     FamilyRHEL,
+	"anolis":      FamilyRHEL,
+	"opencloudos": FamilyRHEL,
+	"tencentos":   FamilyRHEL,
+	"kylin":       FamilyRHEL,
+	"debian":      FamilyDebian,
+	"ubuntu":      FamilyDebian,
+	"linuxmint":   FamilyDebian,
+	"raspbian":    FamilyDebian,
+	"deepin":      FamilyDebian,
+	"uos":         FamilyDebian,
+	"kali":        FamilyDebian,
+	"suse":        FamilySUSE,
+	"opensuse":    FamilySUSE,
+	"sles":        FamilySUSE,
+	"sled":        FamilySUSE,
+	"arch":        FamilyArch,
+	"manjaro":     FamilyArch,
+	"endeavouros": FamilyArch,
+	"alpine":      FamilyAlpine,
+}
+
+// parseOSRelease 解析 os-release 格式的内容
+func parseOSRelease(content string) map[string]string {
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(content))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+			if value[0] == '"' {
+				if unquoted, err := strconv.Unquote(value); err == nil {
+					value = unquoted
+				} else {
+					value = value[1 : len(value)-1]
+				}
+			} else {
+				value = value[1 : len(value)-1]
+			}
+		}
+		fields[strings.TrimSpace(key)] = value
+	}
+	return fields
+}
+
+// readOSRelease 读取 /etc/os-release，不存在时回退到 /usr/lib/os-release
+func readOSRelease() map[string]string {
+	for _, path := range []string{"/etc/os-release", "/usr/lib/os-release"} {
+		if content, err := os.ReadFile(path); err == nil {
+			return parseOSRelease(string(content))
+		}
+	}
+	return nil
+}
+
+// distroFamily 根据 ID_LIKE 与 ID 判断发行版家族，优先使用 ID_LIKE 以正确识别衍生版
+func distroFamily(id string, idLike []string) string {
+	for _, like := range idLike {
+		if family, ok := distroFamilies[like]; ok {
+			return family
+		}
+	}
+	if family, ok := distroFamilies[id]; ok {
+		return family
+	}
+	// opensuse-leap、opensuse-tumbleweed 等
+	if strings.HasPrefix(id, "opensuse") {
+		return FamilySUSE
+	}
+	return ""
+}
+
+// detectDistro 填充发行版信息
+func (si *SystemInfo) detectDistro() {
+	fields := readOSRelease()
+	if fields == nil {
+		return
+	}
+
+	si.ID = strings.ToLower(fields["ID"])
+	si.IDLike = strings.Fields(strings.ToLower(fields["ID_LIKE"]))
+	si.Version = fields["VERSION_ID"]
+	si.Family = distroFamily(si.ID, si.IDLike)
+
+	si.OS = fields["NAME"]
+	if si.OS == "" {
+		si.OS = si.ID
+	}
+}
+
+// MajorVersion 返回主版本号，无法解析时返回 0
+func (si *SystemInfo) MajorVersion() int {
+	major, _, _ := strings.Cut(si.Version, ".")
+	n, err := strconv.Atoi(major)
+	if err != nil {
+		return 0
+	}
+	return n
+}
+
+// DisplayName 返回用于显示的发行版名称
+func (si *SystemInfo) DisplayName() string {
+	if si.OS == "" {
+		return "未知"
+	}
+	name := si.OS
+	if si.Version != "" {
+		name += " " + si.Version
+	}
+	if si.Family != "" {
+		name += " (" + si.Family + ")"
+	}
+	return name
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+	"strings"
+)
+
+// PackageManager 系统包管理器
+type PackageManager struct {
+	Name        string
+	installArgs []string // 安装软件包的参数，软件包名追加在其后
+	refreshArgs []string // 安装前刷新软件包索引的参数，为空表示不需要
+}
+
+// packageManagers 支持的包管理器，按检测优先级排列
+var packageManagers = []*PackageManager{
+	{
+		Name:        "dnf",
+		installArgs: []string{"install", "-y"},
+	},
+	{
+		Name:        "yum",
+		installArgs: []string{"install", "-y"},
+	},
+	{
+		Name:        "apt-get",
+		installArgs: []string{"-y", "install"},
+		refreshArgs: []string{"-y", "update"},
+	},
+	{
+		Name:        "zypper",
+		installArgs: []string{"--non-interactive", "install"},
+	},
+	{
+		// Arch 不支持部分升级 (-Sy 后再 -S)，不单独刷新软件包索引
+		Name:        "pacman",
+		installArgs: []string{"-S", "--needed", "--noconfirm"},
+	},
+	{
+		Name:        "apk",
+		installArgs: []string{"add", "--no-cache"},
+	},
+}
+
+// dependency 运行所需的系统依赖
+type dependency struct {
+	name    string      // 显示名称
+	pkg     string      // 提供该依赖的软件包
+	present func() bool // 检查依赖是否已经存在
+}
+
+// dependencies 运行所需的系统依赖，下载、解压、进程管理均由 Go 实现，只需要 HTTPS 使用的 CA 证书
+var dependencies = []dependency{
+	{name: "CA 证书", pkg: "ca-certificates", present: hasCACertificates},
+}
+
+// caBundleFiles 常见发行版的 CA 证书文件，与 crypto/x509 的查找路径一致
+var caBundleFiles = []string{
+	"/etc/ssl/certs/ca-certificates.crt",
+	"/etc/pki/tls/certs/ca-bundle.crt",
+	"/etc/ssl/ca-bundle.pem",
+	"/etc/pki/tls/cacert.pem",
+	"/etc/pki/ca-trust/extracted/pem/tls-ca-bundle.pem",
+	"/etc/ssl/cert.pem",
+}
+
+// hasCACertificates 检查系统中是否存在 CA 证书
+func hasCACertificates() bool {
+	if file := os.Getenv("SSL_CERT_FILE"); file != "" {
+		return fileExists(file)
+	}
+	for _, file := range caBundleFiles {
+		if stat, err := os.Stat(file); err == nil && stat.Size() > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// familyPackageManager 发行版家族默认使用的包管理器
+var familyPackageManager = map[string]string{
+	FamilyDebian: "apt-get",
+	FamilySUSE:   "zypper",
+	FamilyArch:   "pacman",
+	FamilyAlpine: "apk",
+}
+
+// detectPackageManager 检测系统中可用的包管理器，优先使用发行版家族对应的包管理器
+func detectPackageManager(si *SystemInfo) (*PackageManager, error) {
+	preferred := familyPackageManager[si.Family]
+	if si.Family == FamilyRHEL {
+		// RHEL 8 及以后的版本 (含 Fedora、Amazon Linux 2023、openEuler 等) 使用 dnf
+		preferred = "yum"
+		if si.MajorVersion() >= 8 {
+			preferred = "dnf"
+		}
+	}
+
+	for _, pm := range packageManagers {
+		if pm.Name != preferred {
+			continue
+		}
+		if _, err := exec.LookPath(pm.Name); err == nil {
+			return pm, nil
+		}
+	}
+
+	for _, pm := range packageManagers {
+		if _, err := exec.LookPath(pm.Name); err == nil {
+			return pm, nil
+		}
+	}
+	return nil, fmt.Errorf("未找到支持的包管理器 (dnf, yum, apt-get, zypper, pacman, apk)")
+}
+
+// command 创建包管理器命令，apt-get 以非交互方式运行
+func (pm *PackageManager) command(args ...string) *exec.Cmd {
+	cmd := exec.Command(pm.Name, args...)
+	if pm.Name == "apt-get" {
+		cmd.Env = append(os.Environ(), "DEBIAN_FRONTEND=noninteractive")
+	}
+	return cmd
+}
+
+// PackageError 软件包安装失败的信息
+type PackageError struct {
+	Failures map[string]string // 软件包名 -> 命令输出
+}
+
+func (e *PackageError) Error() string {
+	names := make([]string, 0, len(e.Failures))
+	for name := range e.Failures {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString("以下软件包安装失败:")
+	for _, name := range names {
+		sb.WriteString(fmt.Sprintf("\n  - %s:\n%s", name, indentOutput(e.Failures[name], 10)))
+	}
+	return sb.String()
+}
+
+// indentOutput 缩进命令输出，只保留最后 maxLines 行
+func indentOutput(output string, maxLines int) string {
+	lines := strings.Split(strings.TrimRight(output, "\n"), "\n")
+	if len(lines) > maxLines {
+		lines = lines[len(lines)-maxLines:]
+	}
+	for i, line := range lines {
+		lines[i] = "      " + line
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Refresh 刷新软件包索引
+func (pm *PackageManager) Refresh() error {
+	if len(pm.refreshArgs) == 0 {
+		return nil
+	}
+	output, err := pm.command(pm.refreshArgs...).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("更新软件包索引失败: %v\n%s", err, indentOutput(string(output), 10))
+	}
+	return nil
+}
+
+// Install 安装软件包，整体安装失败时逐个重试以找出具体失败的软件包
+func (pm *PackageManager) Install(pkgs []string) error {
+	args := append(append([]string{}, pm.installArgs...), pkgs...)
+	if _, err := pm.command(args...).CombinedOutput(); err == nil {
+		return nil
+	}
+
+	failures := make(map[string]string)
+	for _, pkg := range pkgs {
+		args := append(append([]string{}, pm.installArgs...), pkg)
+		output, err := pm.command(args...).CombinedOutput()
+		if err != nil {
+			failures[pkg] = fmt.Sprintf("%v\n%s", err, strings.TrimSpace(string(output)))
+		}
+	}
+	if len(failures) == 0 {
+		return nil
+	}
+	return &PackageError{Failures: failures}
+}
+
+// missingDependencies 返回系统中缺失的依赖
+func missingDependencies() []dependency {
+	var deps []dependency
+	for _, dep := range dependencies {
+		if !dep.present() {
+			deps = append(deps, dep)
+		}
+	}
+	return deps
+}
+
+// dependencyPackages 返回提供指定依赖的软件包 (已去重)
+func dependencyPackages(deps []dependency) []string {
+	seen := make(map[string]bool)
+	var pkgs []string
+	for _, dep := range deps {
+		if !seen[dep.pkg] {
+			seen[dep.pkg] = true
+			pkgs = append(pkgs, dep.pkg)
+		}
+	}
+	return pkgs
+}
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// AutoSource 自动选择最快下载源的名称
+const AutoSource = "auto"
+
+// 测速参数
+const (
+	probeBytes       = 256 << 10        // 发布包测速下载的字节数
+	probeTimeout     = 10 * time.Second // 单个下载源的测速超时
+	probeArchiveSize = 12 << 20         // 估算下载耗时使用的发布包大小
+)
+
+// probeResult 单个下载源的测速结果
+type probeResult struct {
+	Mirror     *Mirror
+	Version    string        // 版本接口返回的版本
+	APILatency time.Duration // 版本接口响应时间
+	Latency    time.Duration // 发布包首字节时间
+	Throughput float64       // 发布包下载速度，字节/秒
+	APIErr     error
+	Err        error // 发布包不可用时的错误
+}
+
+// estimate 估算下载整个发布包的耗时，同时考虑延迟与速度
+func (r *probeResult) estimate() time.Duration {
+	if r.Throughput <= 0 {
+		return r.Latency + time.Hour
+	}
+	return r.Latency + time.Duration(float64(probeArchiveSize)/r.Throughput*float64(time.Second))
+}
+
+// probeAPI 请求下载源的版本接口，version 为空时获取最新版本
+func probeAPI(ctx context.Context, mirror *Mirror, version string, prerelease bool) (string, time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	url := mirror.latestAPI()
+	switch {
+	case version != "":
+		url = mirror.releasesAPI() + "/tags/v" + version
+	case prerelease:
+		url = fmt.Sprintf("%s?page=1&per_page=1", mirror.releasesAPI())
+		if mirror.isGitee() {
+			url += "&direction=desc"
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", 0, err
+	}
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return "", 0, err
+	}
+	defer resp.Body.Close()
+	latency := time.Since(start)
+
+	if resp.StatusCode != http.StatusOK {
+		return "", latency, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	var release Release
+	if version == "" && prerelease {
+		var releases []Release
+		if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+			return "", latency, err
+		}
+		if len(releases) == 0 {
+			return "", latency, fmt.Errorf("没有可用的版本")
+		}
+		release = releases[0]
+	} else if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", latency, err
+	}
+	return release.Version(), latency, nil
+}
+
+// probeAsset 通过 Range 请求下载发布包的开头部分，测量首字节时间与下载速度
+func probeAsset(ctx context.Context, url string) (time.Duration, float64, error) {
+	ctx, cancel := context.WithTimeout(ctx, probeTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=0-%d", probeBytes-1))
+
+	start := time.Now()
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+	latency := time.Since(start)
+
+	// 不支持 Range 的服务器返回 200，只读取前 probeBytes 字节
+	if resp.StatusCode != http.StatusPartialContent && resp.StatusCode != http.StatusOK {
+		return latency, 0, fmt.Errorf("HTTP %d", resp.StatusCode)
+	}
+
+	n, err := io.Copy(io.Discard, io.LimitReader(resp.Body, probeBytes))
+	elapsed := time.Since(start) - latency
+	if err != nil && n == 0 {
+		return latency, 0, err
+	}
+	if elapsed <= 0 {
+		elapsed = time.Millisecond
+	}
+	return latency, float64(n) / elapsed.Seconds(), nil
+}
+
+// compareVersions 比较两个版本号，正式版本高于同号的预发布版本
+func compareVersions(a, b string) int {
+	coreA, preA, _ := strings.Cut(a, "-")
+	coreB, preB, _ := strings.Cut(b, "-")
+	partsA, partsB := strings.Split(coreA, "."), strings.Split(coreB, ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		var x, y int
+		if i < len(partsA) {
+			x, _ = strconv.Atoi(partsA[i])
+		}
+		if i < len(partsB) {
+			y, _ = strconv.Atoi(partsB[i])
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return strings.Compare(preA, preB)
+}
+
+// autoSelectMirror 并行测速所有下载源，选择提供目标版本且预计下载耗时最短的下载源
+func (fm *FrpsManager) autoSelectMirror(ctx context.Context, mirrors []*Mirror, opts *DownloadOptions) (*Mirror, error) {
+	fm.Colors["green"].Printf("正在测速 %d 个下载源...\n", len(mirrors))
+
+	results := make([]*probeResult, len(mirrors))
+	var wg sync.WaitGroup
+	for i, m := range mirrors {
+		results[i] = &probeResult{Mirror: m}
+		wg.Add(1)
+		go func(r *probeResult) {
+			defer wg.Done()
+			r.Version, r.APILatency, r.APIErr = probeAPI(ctx, r.Mirror, opts.Version, opts.Prerelease)
+		}(results[i])
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// 目标版本为指定的版本，未指定时取各下载源中最新的版本，落后的镜像会在下一步被排除
+	version := opts.Version
+	if version == "" {
+		for _, r := range results {
+			if r.APIErr == nil && (version == "" || compareVersions(r.Version, version) > 0) {
+				version = r.Version
+			}
+		}
+		if version == "" {
+			var reasons []string
+			for _, r := range results {
+				reasons = append(reasons, fmt.Sprintf("  - %s: %v", r.Mirror.Name, r.APIErr))
+			}
+			return nil, fmt.Errorf("所有下载源都无法获取最新版本:\n%s", strings.Join(reasons, "\n"))
+		}
+	}
+
+	filename := fmt.Sprintf("frp_%s_linux_%s.tar.gz", version, fm.SystemInfo.FrpsArch)
+	for _, r := range results {
+		wg.Add(1)
+		go func(r *probeResult) {
+			defer wg.Done()
+			r.Latency, r.Throughput, r.Err = probeAsset(ctx, r.Mirror.URL(version, fm.SystemInfo.FrpsArch, filename))
+		}(r)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	var available []*probeResult
+	for _, r := range results {
+		if r.Err == nil {
+			available = append(available, r)
+		}
+	}
+	sort.SliceStable(available, func(i, j int) bool { return available[i].estimate() < available[j].estimate() })
+
+	fm.showProbeResults(results, version)
+
+	if len(available) == 0 {
+		return nil, fmt.Errorf("没有可用的下载源提供版本 %s", version)
+	}
+
+	best := available[0]
+	opts.probedVersion = version
+	fmt.Println("-----------------------------------")
+	fm.Colors["yellow"].Printf("       自动选择: %s\n", best.Mirror.Name)
+	fmt.Println("-----------------------------------")
+	return best.Mirror, nil
+}
+
+// showProbeResults 输出测速结果
+func (fm *FrpsManager) showProbeResults(results []*probeResult, version string) {
+	fmt.Printf("目标版本: %s\n", version)
+	fmt.Printf("  %s %s %s %s %s\n", padDisplay("下载源", 12), padDisplay("接口延迟", 10), padDisplay("首字节", 10), padDisplay("速度", 12), "结果")
+	for _, r := range results {
+		api := "-"
+		if r.APIErr == nil {
+			api = r.APILatency.Round(time.Millisecond).String()
+		}
+		if r.Err != nil {
+			fmt.Printf("  %-12s %-10s %-10s %-12s %s\n", r.Mirror.Name, api, "-", "-",
+				fm.Colors["red"].Sprintf("✗ %v", r.Err))
+			continue
+		}
+		fmt.Printf("  %-12s %-10s %-10s %-12s %s\n", r.Mirror.Name, api,
+			r.Latency.Round(time.Millisecond).String(),
+			fm.formatBytes(int64(r.Throughput))+"/s",
+			fm.Colors["green"].Sprintf("✓ 预计 %s", r.estimate().Round(time.Millisecond)))
+	}
+}
+
+// padDisplay 按终端显示宽度补齐空格，中文字符占两列
+func padDisplay(s string, width int) string {
+	w := 0
+	for _, r := range s {
+		if r >= 0x1100 {
+			w += 2
+		} else {
+			w++
+		}
+	}
+	if w >= width {
+		return s
+	}
+	return s + strings.Repeat(" ", width-w)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Process 从 /proc 读取的进程信息
+type Process struct {
+	PID     int
+	Name    string   // 进程名 (/proc/<pid>/comm)
+	Exe     string   // 可执行文件路径 (/proc/<pid>/exe)
+	Cmdline []string // 命令行参数
+	RSS     int64    // 常驻内存，单位字节
+}
+
+// findProcesses 查找进程名与 name 完全相同的进程，行为与 pgrep -x 一致
+func findProcesses(name string) ([]Process, error) {
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, err
+	}
+
+	self := os.Getpid()
+	var procs []Process
+	for _, entry := range entries {
+		pid, err := strconv.Atoi(entry.Name())
+		if err != nil || pid == self {
+			continue
+		}
+
+		// 进程可能在遍历过程中退出，读取失败时直接跳过
+		comm, err := os.ReadFile(filepath.Join("/proc", entry.Name(), "comm"))
+		if err != nil || strings.TrimSpace(string(comm)) != name {
+			continue
+		}
+
+		procs = append(procs, readProcess(pid, name))
+	}
+
+	sort.Slice(procs, func(i, j int) bool { return procs[i].PID < procs[j].PID })
+	return procs, nil
+}
+
+// readProcess 读取进程的命令行、可执行文件与内存占用
+func readProcess(pid int, name string) Process {
+	dir := filepath.Join("/proc", strconv.Itoa(pid))
+	proc := Process{PID: pid, Name: name}
+
+	proc.Exe, _ = os.Readlink(filepath.Join(dir, "exe"))
+
+	if cmdline, err := os.ReadFile(filepath.Join(dir, "cmdline")); err == nil {
+		proc.Cmdline = strings.Split(strings.TrimRight(string(cmdline), "\x00"), "\x00")
+	}
+
+	// statm 第二列为常驻内存页数
+	if statm, err := os.ReadFile(filepath.Join(dir, "statm")); err == nil {
+		fields := strings.Fields(string(statm))
+		if len(fields) > 1 {
+			if pages, err := strconv.ParseInt(fields[1], 10, 64); err == nil {
+				proc.RSS = pages * int64(os.Getpagesize())
+			}
+		}
+	}
+
+	return proc
+}
@@ -1,10 +1,9 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"io"
-	"net/http"
-	"os"
 	"strings"
 	"sync/atomic"
 	"time"
@@ -16,6 +15,8 @@ type ProgressReader struct {
 	total    int64
 	current  int64
 	progress chan ProgressUpdate
+	done     chan struct{} // 关闭后停止进度显示
+	stopped  chan struct{} // 进度显示结束后关闭
 }
 
 // ProgressUpdate 进度更新信息
@@ -31,6 +32,8 @@ func NewProgressReader(r io.Reader, total int64) *ProgressReader {
 		Reader:   r,
 		total:    total,
 		progress: make(chan ProgressUpdate, 1),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
 	}
 }
 
@@ -38,22 +41,41 @@ func NewProgressReader(r io.Reader, total int64) *ProgressReader {
 func (pr *ProgressReader) Read(p []byte) (int, error) {
 	n, err := pr.Reader.Read(p)
 	if n > 0 {
-		atomic.AddInt64(&pr.current, int64(n))
-		current := atomic.LoadInt64(&pr.current)
-		percent := float64(current) / float64(pr.total) * 100
-		
-		// 非阻塞发送进度更新
-		select {
-		case pr.progress <- ProgressUpdate{Current: current, Total: pr.total, Percent: percent}:
-		default:
-		}
+		pr.Add(int64(n))
 	}
 	return n, err
 }
 
+// Add 增加已完成的字节数，分段下载与断点续传直接调用
+func (pr *ProgressReader) Add(n int64) {
+	current := atomic.AddInt64(&pr.current, n)
+	percent := float64(current) / float64(pr.total) * 100
+
+	// 非阻塞发送进度更新，丢弃尚未显示的旧进度
+	select {
+	case <-pr.progress:
+	default:
+	}
+	select {
+	case pr.progress <- ProgressUpdate{Current: current, Total: pr.total, Percent: percent}:
+	default:
+	}
+}
+
+// Finish 停止进度显示并等待最后一次输出完成，下载失败时同样需要调用
+func (pr *ProgressReader) Finish() {
+	select {
+	case <-pr.done:
+	default:
+		close(pr.done)
+	}
+	<-pr.stopped
+}
+
 // StartProgress 启动进度显示
 func (pr *ProgressReader) StartProgress(description string, fm *FrpsManager) {
 	go func() {
+		defer close(pr.stopped)
 		ticker := time.NewTicker(100 * time.Millisecond) // 每100ms更新一次
 		defer ticker.Stop()
 		
@@ -66,12 +88,17 @@ func (pr *ProgressReader) StartProgress(description string, fm *FrpsManager) {
 				if lastUpdate.Total > 0 {
 					fm.showProgress(description, lastUpdate)
 				}
-			}
-			
-			// 下载完成时退出
-			if lastUpdate.Current >= lastUpdate.Total && lastUpdate.Total > 0 {
-				fm.showProgress(description, lastUpdate)
-				fmt.Println() // 换行
+			case <-pr.done:
+				// 下载结束或失败，输出最终进度后退出
+				select {
+				case update := <-pr.progress:
+					lastUpdate = update
+				default:
+				}
+				if lastUpdate.Total > 0 {
+					fm.showProgress(description, lastUpdate)
+					fmt.Println() // 换行
+				}
 				return
 			}
 		}
@@ -113,88 +140,14 @@ func (fm *FrpsManager) formatBytes(bytes int64) string {
 	return fmt.Sprintf("%.1f %cB", float64(bytes)/float64(div), "KMGTPE"[exp])
 }
 
-// downloadWithProgress 带进度条的下载函数
-func (fm *FrpsManager) downloadWithProgress(url, filename, description string) error {
-	resp, err := http.Get(url)
+// downloadWithProgress 带进度条的下载函数，服务器支持 Range 时分段并发下载并支持断点续传
+func (fm *FrpsManager) downloadWithProgress(ctx context.Context, url, filename, description string) error {
+	info, err := statRemoteFile(ctx, url)
 	if err != nil {
 		return err
 	}
-	defer resp.Body.Close()
-	
-	// 获取文件大小
-	total := resp.ContentLength
-	if total <= 0 {
-		total = 1 // 避免除零错误，对于未知大小的文件
+	if !info.AcceptRanges {
+		return fm.downloadStream(ctx, url, filename, description)
 	}
-	
-	// 创建目标文件
-	tmpFile, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer tmpFile.Close()
-	
-	// 创建进度Reader
-	progressReader := NewProgressReader(resp.Body, total)
-	progressReader.StartProgress(description, fm)
-	
-	// 复制数据
-	_, err = io.Copy(tmpFile, progressReader)
-	if err != nil {
-		return err
-	}
-	
-	// 等待进度显示完成
-	time.Sleep(200 * time.Millisecond)
-	
-	return nil
-}
-
-// downloadWithProgressForScript 为脚本下载提供的带进度条下载功能
-func (fm *FrpsManager) downloadWithProgressForScript(url, filename, description string) error {
-	resp, err := http.Get(url)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-	
-	// 获取文件大小
-	total := resp.ContentLength
-	if total <= 0 {
-		// 对于脚本文件，如果没有Content-Length，假设一个小的默认值
-		total = 8192 // 8KB
-	}
-	
-	// 创建目标文件
-	tmpFile, err := os.Create(filename)
-	if err != nil {
-		return err
-	}
-	defer tmpFile.Close()
-	
-	// 由于脚本文件通常很小，我们使用一个简化的进度显示
-	if total > 1024*10 { // 只有大于10KB的文件才显示进度条
-		// 创建进度Reader
-		progressReader := NewProgressReader(resp.Body, total)
-		progressReader.StartProgress(description, fm)
-		
-		// 复制数据
-		_, err = io.Copy(tmpFile, progressReader)
-		if err != nil {
-			return err
-		}
-		
-		// 等待进度显示完成
-		time.Sleep(200 * time.Millisecond)
-	} else {
-		// 对于小文件，直接复制不显示进度条
-		fmt.Printf("%s...", description)
-		_, err = io.Copy(tmpFile, resp.Body)
-		if err != nil {
-			return err
-		}
-		fmt.Println(" 完成")
-	}
-	
-	return nil
-} 
\ No newline at end of file
+	return fm.downloadSegmented(ctx, url, filename, description, info)
+}
\ No newline at end of file
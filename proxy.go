@@ -0,0 +1,447 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+)
+
+// proxyTypes client proxy add 支持的代理类型
+var proxyTypes = []string{"tcp", "udp", "http", "https", "stcp"}
+
+// proxyNameRe 代理名称允许的字符
+var proxyNameRe = regexp.MustCompile(`^[A-Za-z0-9_.\-]+$`)
+
+// ProxyConfig frpc.toml 中的一个 [[proxies]]
+type ProxyConfig struct {
+	Name          string   `toml:"name"`
+	Type          string   `toml:"type"`
+	LocalIP       string   `toml:"localIP"`
+	LocalPort     int      `toml:"localPort"`
+	RemotePort    int      `toml:"remotePort"`
+	CustomDomains []string `toml:"customDomains"`
+	SubDomain     string   `toml:"subdomain"`
+	SecretKey     string   `toml:"secretKey"`
+}
+
+// ProxyOptions client proxy 子命令的选项
+type ProxyOptions struct {
+	Proxy         ProxyConfig
+	AllowPorts    string // 服务端允许的端口，未指定时使用 client install 保存的值或本机 frps.toml
+	SubDomainHost string // 服务端的 subDomainHost，未指定时使用 client install 保存的值或本机 frps.toml
+}
+
+// portRange frps allowPorts 中的一项
+type portRange struct {
+	Start  int `toml:"start"`
+	End    int `toml:"end"`
+	Single int `toml:"single"`
+}
+
+// contains 检查端口是否在范围内
+func (r portRange) contains(port int) bool {
+	if r.Single != 0 {
+		return port == r.Single
+	}
+	return port >= r.Start && port <= r.End
+}
+
+func (r portRange) String() string {
+	if r.Single != 0 {
+		return strconv.Itoa(r.Single)
+	}
+	return fmt.Sprintf("%d-%d", r.Start, r.End)
+}
+
+// parsePortRanges 解析 2000-3000,3001 格式的端口范围
+func parsePortRanges(value string) ([]portRange, error) {
+	var ranges []portRange
+	for _, item := range strings.Split(value, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+		start, end, isRange := strings.Cut(item, "-")
+		from, err := strconv.Atoi(strings.TrimSpace(start))
+		if err != nil || from < 1 || from > 65535 {
+			return nil, fmt.Errorf("无效的端口范围: %q", item)
+		}
+		if !isRange {
+			ranges = append(ranges, portRange{Single: from})
+			continue
+		}
+		to, err := strconv.Atoi(strings.TrimSpace(end))
+		if err != nil || to < from || to > 65535 {
+			return nil, fmt.Errorf("无效的端口范围: %q", item)
+		}
+		ranges = append(ranges, portRange{Start: from, End: to})
+	}
+	return ranges, nil
+}
+
+// serverLimits 校验代理时使用的服务端限制，known 为 false 表示无法得知服务端配置
+type serverLimits struct {
+	known         bool
+	allowPorts    []portRange
+	subDomainHost string
+}
+
+// serverLimitsPath 返回 client install 保存服务端限制的文件，格式与 frps.toml 中的对应字段相同
+func serverLimitsPath() string {
+	return filepath.Join(ClientComponent.Dir, "frps-limits.toml")
+}
+
+// saveServerLimits 保存 frpc 连接的服务端的限制，未指定的字段保留原有的值
+func saveServerLimits(allowPorts, subDomainHost string) error {
+	file := &serverFile{}
+	if existing, err := readServerFile(serverLimitsPath()); err == nil {
+		file = existing
+	}
+	if allowPorts != "" {
+		ranges, err := parsePortRanges(allowPorts)
+		if err != nil {
+			return err
+		}
+		file.AllowPorts = ranges
+	}
+	if subDomainHost != "" {
+		file.SubDomainHost = subDomainHost
+	}
+
+	var b strings.Builder
+	b.WriteString("# frpc 连接的 frps 的限制，由 'frps-onekey client install --allow-ports/--subdomain-host' 写入，\n")
+	b.WriteString("# client proxy add 按此校验代理\n")
+	if file.SubDomainHost != "" {
+		fmt.Fprintf(&b, "subDomainHost = %s\n", tomlString(file.SubDomainHost))
+	}
+	if len(file.AllowPorts) > 0 {
+		items := make([]string, len(file.AllowPorts))
+		for i, r := range file.AllowPorts {
+			if r.Single != 0 {
+				items[i] = fmt.Sprintf("{ single = %d }", r.Single)
+			} else {
+				items[i] = fmt.Sprintf("{ start = %d, end = %d }", r.Start, r.End)
+			}
+		}
+		fmt.Fprintf(&b, "allowPorts = [%s]\n", strings.Join(items, ", "))
+	}
+	return writeFileAtomic(serverLimitsPath(), []byte(b.String()), 0644)
+}
+
+// loadServerLimits 依次使用本机的 frps.toml、client install 保存的服务端限制与命令行参数，后者优先
+func loadServerLimits(opts *ProxyOptions) (*serverLimits, error) {
+	limits := &serverLimits{}
+	for _, path := range []string{ServerComponent.ConfigPath(), serverLimitsPath()} {
+		if file, err := readServerFile(path); err == nil {
+			limits.known = true
+			limits.allowPorts = file.AllowPorts
+			limits.subDomainHost = file.SubDomainHost
+		}
+	}
+	if opts.AllowPorts != "" {
+		ranges, err := parsePortRanges(opts.AllowPorts)
+		if err != nil {
+			return nil, err
+		}
+		limits.known = true
+		limits.allowPorts = ranges
+	}
+	if opts.SubDomainHost != "" {
+		limits.known = true
+		limits.subDomainHost = opts.SubDomainHost
+	}
+	return limits, nil
+}
+
+// validate 校验代理配置，existing 为 frpc.toml 中已有的代理
+func (p *ProxyConfig) validate(existing []ProxyConfig, limits *serverLimits) FieldErrors {
+	var errs FieldErrors
+
+	if !proxyNameRe.MatchString(p.Name) {
+		errs = append(errs, FieldError{"name", "只能包含字母、数字、下划线、点和连字符"})
+	}
+	for _, other := range existing {
+		if other.Name == p.Name {
+			errs = append(errs, FieldError{"name", fmt.Sprintf("代理 %q 已存在", p.Name)})
+			break
+		}
+	}
+	if !containsString(proxyTypes, p.Type) {
+		errs = append(errs, FieldError{"type", fmt.Sprintf("无效的代理类型 %q (可选 %s)", p.Type, strings.Join(proxyTypes, ", "))})
+	}
+	if strings.ContainsAny(p.LocalIP, "\"\\\r\n") || p.LocalIP == "" {
+		errs = append(errs, FieldError{"local_ip", "无效的地址"})
+	}
+	if p.LocalPort < 1 || p.LocalPort > 65535 {
+		errs = append(errs, FieldError{"local_port", fmt.Sprintf("端口 %d 超出范围 [1-65535]", p.LocalPort)})
+	}
+
+	switch p.Type {
+	case "tcp", "udp":
+		errs = append(errs, p.validateRemotePort(limits)...)
+	case "http", "https":
+		errs = append(errs, p.validateDomains(limits)...)
+	case "stcp":
+		if p.SecretKey == "" || strings.ContainsAny(p.SecretKey, "\"\\\r\n") {
+			errs = append(errs, FieldError{"secret_key", "不能为空，且不能包含引号、反斜杠或换行符"})
+		}
+	}
+	return errs
+}
+
+// validateRemotePort 检查 tcp/udp 的远程端口是否在服务端允许的范围内
+func (p *ProxyConfig) validateRemotePort(limits *serverLimits) FieldErrors {
+	if p.RemotePort < 1 || p.RemotePort > 65535 {
+		return FieldErrors{{"remote_port", fmt.Sprintf("端口 %d 超出范围 [1-65535]", p.RemotePort)}}
+	}
+	if len(limits.allowPorts) == 0 {
+		return nil
+	}
+	var allowed []string
+	for _, r := range limits.allowPorts {
+		if r.contains(p.RemotePort) {
+			return nil
+		}
+		allowed = append(allowed, r.String())
+	}
+	return FieldErrors{{"remote_port", fmt.Sprintf("端口 %d 不在服务端 allowPorts 范围内 (%s)", p.RemotePort, strings.Join(allowed, ","))}}
+}
+
+// validateDomains 检查 http/https 的域名与服务端 subDomainHost 是否匹配
+func (p *ProxyConfig) validateDomains(limits *serverLimits) FieldErrors {
+	var errs FieldErrors
+	if len(p.CustomDomains) == 0 && p.SubDomain == "" {
+		return FieldErrors{{"custom_domains", "http/https 代理需要指定 --custom-domains 或 --subdomain"}}
+	}
+
+	host := limits.subDomainHost
+	if p.SubDomain != "" {
+		switch {
+		case strings.ContainsAny(p.SubDomain, ".*\"\\\r\n "):
+			errs = append(errs, FieldError{"subdomain", "不能包含点、星号、引号或空白字符"})
+		case limits.known && host == "":
+			errs = append(errs, FieldError{"subdomain", "服务端未设置 subDomainHost，无法使用 subdomain"})
+		}
+	}
+	for _, domain := range p.CustomDomains {
+		if strings.ContainsAny(domain, "\"\\\r\n ") {
+			errs = append(errs, FieldError{"custom_domains", fmt.Sprintf("无效的域名 %q", domain)})
+		} else if host != "" && strings.HasSuffix(domain, "."+host) {
+			// frps 不允许自定义域名落在 subDomainHost 之下，应改用 subdomain
+			errs = append(errs, FieldError{"custom_domains", fmt.Sprintf("%s 属于 subDomainHost %s，请使用 --subdomain %s", domain, host, strings.TrimSuffix(domain, "."+host))})
+		}
+	}
+	return errs
+}
+
+// lines 生成 [[proxies]] 配置块
+func (p *ProxyConfig) lines() []string {
+	lines := []string{
+		"[[proxies]]",
+		fmt.Sprintf("name = %q", p.Name),
+		fmt.Sprintf("type = %q", p.Type),
+		fmt.Sprintf("localIP = %q", p.LocalIP),
+		fmt.Sprintf("localPort = %d", p.LocalPort),
+	}
+	switch p.Type {
+	case "tcp", "udp":
+		lines = append(lines, fmt.Sprintf("remotePort = %d", p.RemotePort))
+	case "http", "https":
+		if len(p.CustomDomains) > 0 {
+			quoted := make([]string, len(p.CustomDomains))
+			for i, domain := range p.CustomDomains {
+				quoted[i] = fmt.Sprintf("%q", domain)
+			}
+			lines = append(lines, fmt.Sprintf("customDomains = [%s]", strings.Join(quoted, ", ")))
+		}
+		if p.SubDomain != "" {
+			lines = append(lines, fmt.Sprintf("subdomain = %q", p.SubDomain))
+		}
+	case "stcp":
+		lines = append(lines, fmt.Sprintf("secretKey = %q", p.SecretKey))
+	}
+	return lines
+}
+
+// target 返回代理在服务端的访问方式，用于列表显示
+func (p *ProxyConfig) target(subDomainHost string) string {
+	switch p.Type {
+	case "tcp", "udp":
+		return strconv.Itoa(p.RemotePort)
+	case "http", "https":
+		domains := append([]string{}, p.CustomDomains...)
+		if p.SubDomain != "" {
+			if subDomainHost != "" {
+				domains = append(domains, p.SubDomain+"."+subDomainHost)
+			} else {
+				domains = append(domains, p.SubDomain+".<subDomainHost>")
+			}
+		}
+		return strings.Join(domains, ",")
+	default:
+		return "-"
+	}
+}
+
+// readProxies 解析 frpc.toml 中的代理
+func readProxies(path string) ([]ProxyConfig, error) {
+	var file struct {
+		Proxies []ProxyConfig `toml:"proxies"`
+	}
+	if _, err := toml.DecodeFile(path, &file); err != nil {
+		return nil, fmt.Errorf("解析 %s 失败: %v", path, err)
+	}
+	return file.Proxies, nil
+}
+
+// replaceConfig 将新的配置写入临时文件校验，通过后替换原文件并保存为历史版本，command 为记录在历史中的命令
+func (fm *FrpsManager) replaceConfig(content []byte, command string) error {
+	configPath := fm.Component.ConfigPath()
+	tmp, err := createTempFile(configPath, secretFileMode, func(f *os.File) error {
+		_, err := f.Write(content)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("写入临时文件失败: %v", err)
+	}
+	if err := fm.verifyConfig(tmp); err != nil {
+		os.Remove(tmp)
+		return fmt.Errorf("配置校验失败，未做修改:\n%v", err)
+	}
+
+	fm.recordExistingConfig()
+	if err := commitTempFile(tmp, configPath); err != nil {
+		return fmt.Errorf("替换配置文件失败: %v", err)
+	}
+	fm.Colors["green"].Println("✓ 配置校验通过，已保存")
+	fm.recordConfig(command)
+	return nil
+}
+
+// applyConfigChange 校验并保存修改后的配置，然后询问是否重启
+func (fm *FrpsManager) applyConfigChange(content []byte, command string) error {
+	if err := fm.replaceConfig(content, command); err != nil {
+		return err
+	}
+	if fm.confirm(fmt.Sprintf("是否重启 %s 服务以应用新配置？(y/n): ", fm.Component.Name)) {
+		if fm.isInstalled() {
+			fm.Restart()
+		} else {
+			fm.Colors["yellow"].Printf("%s 服务未运行，请使用 '%s start' 启动服务\n", fm.Component.Name, fm.Component.Command)
+		}
+	}
+	return nil
+}
+
+// readClientDoc 读取 frpc.toml 及其中的代理
+func (fm *FrpsManager) readClientDoc() (*tomlDoc, []ProxyConfig, error) {
+	configPath := fm.Component.ConfigPath()
+	content, err := os.ReadFile(configPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("配置文件 %s 不存在，请先执行 'frps-onekey client install'", configPath)
+		}
+		return nil, nil, err
+	}
+	proxies, err := readProxies(configPath)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseTOMLDoc(content), proxies, nil
+}
+
+// ProxyAdd 添加代理
+func (fm *FrpsManager) ProxyAdd(opts *ProxyOptions) {
+	if !fm.checkRoot() {
+		return
+	}
+	doc, proxies, err := fm.readClientDoc()
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	limits, err := loadServerLimits(opts)
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	if !limits.known {
+		fm.Colors["yellow"].Println("未找到本机 frps 配置，跳过 allowPorts 与 subDomainHost 检查 (可用 --allow-ports、--subdomain-host 指定)")
+	}
+
+	proxy := &opts.Proxy
+	if proxy.Type == "stcp" && proxy.SecretKey == "" {
+		proxy.SecretKey = fm.generateRandomString(16)
+		fm.Colors["yellow"].Printf("已生成 secretKey: %s (访问端需要使用相同的值)\n", proxy.SecretKey)
+	}
+	if errs := proxy.validate(proxies, limits); len(errs) > 0 {
+		fm.Colors["red"].Printf("代理配置无效:\n%v\n", errs)
+		return
+	}
+
+	doc.appendBlock(proxy.lines())
+	if err := fm.applyConfigChange(doc.Bytes(), "client proxy add "+proxy.Name); err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	fm.Colors["green"].Printf("已添加代理 %s\n", proxy.Name)
+}
+
+// ProxyRemove 按名称删除代理，保留文件中的其他内容与注释
+func (fm *FrpsManager) ProxyRemove(name string) {
+	if !fm.checkRoot() {
+		return
+	}
+	doc, _, err := fm.readClientDoc()
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+
+	for _, t := range doc.tables() {
+		if !t.Array || t.Name != "proxies" {
+			continue
+		}
+		if value, ok := doc.value(t, "name"); !ok || value != name {
+			continue
+		}
+		doc.remove(t.Start, t.End)
+		if err := fm.applyConfigChange(doc.Bytes(), "client proxy remove "+name); err != nil {
+			fm.Colors["red"].Printf("%v\n", err)
+			return
+		}
+		fm.Colors["green"].Printf("已删除代理 %s\n", name)
+		return
+	}
+	fm.Colors["red"].Printf("代理 %q 不存在\n", name)
+}
+
+// ProxyList 列出 frpc.toml 中的代理
+func (fm *FrpsManager) ProxyList() {
+	_, proxies, err := fm.readClientDoc()
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	if len(proxies) == 0 {
+		fmt.Println("尚未配置代理，使用 'frps-onekey client proxy add' 添加")
+		return
+	}
+
+	var subDomainHost string
+	if file, err := readServerFile(ServerComponent.ConfigPath()); err == nil {
+		subDomainHost = file.SubDomainHost
+	}
+
+	fmt.Printf("============== frpc 代理 (%s) ==============\n", fm.Component.ConfigPath())
+	for _, p := range proxies {
+		local := fmt.Sprintf("%s:%d", p.LocalIP, p.LocalPort)
+		fmt.Printf("  %-16s %-6s %-22s %s\n", p.Name, p.Type, local, p.target(subDomainHost))
+	}
+	fmt.Printf("共 %d 个代理\n", len(proxies))
+}
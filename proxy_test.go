@@ -0,0 +1,55 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParsePortRanges(t *testing.T) {
+	tests := []struct {
+		value string
+		want  []portRange
+		ok    bool
+	}{
+		{"", nil, true},
+		{"6000", []portRange{{Single: 6000}}, true},
+		{"2000-3000", []portRange{{Start: 2000, End: 3000}}, true},
+		{" 2000 - 3000 , 3001,,", []portRange{{Start: 2000, End: 3000}, {Single: 3001}}, true},
+		{"1-65535", []portRange{{Start: 1, End: 65535}}, true},
+		{"3000-3000", []portRange{{Start: 3000, End: 3000}}, true},
+		{"0", nil, false},
+		{"65536", nil, false},
+		{"3000-2000", nil, false},
+		{"2000-70000", nil, false},
+		{"2000-", nil, false},
+		{"-2000", nil, false},
+		{"abc", nil, false},
+		{"2000-3000-4000", nil, false},
+	}
+	for _, tt := range tests {
+		got, err := parsePortRanges(tt.value)
+		if (err == nil) != tt.ok || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parsePortRanges(%q) = %v, %v; want %v, ok=%v", tt.value, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestPortRangeContains(t *testing.T) {
+	tests := []struct {
+		r    portRange
+		port int
+		want bool
+	}{
+		{portRange{Single: 6000}, 6000, true},
+		{portRange{Single: 6000}, 6001, false},
+		{portRange{Start: 2000, End: 3000}, 2000, true},
+		{portRange{Start: 2000, End: 3000}, 3000, true},
+		{portRange{Start: 2000, End: 3000}, 1999, false},
+		{portRange{Start: 2000, End: 3000}, 3001, false},
+	}
+	for _, tt := range tests {
+		if got := tt.r.contains(tt.port); got != tt.want {
+			t.Errorf("%v.contains(%d) = %v, want %v", tt.r, tt.port, got, tt.want)
+		}
+	}
+}
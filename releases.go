@@ -0,0 +1,178 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// 版本列表分页参数
+const (
+	DefaultReleasesPerPage = 20
+	MaxReleasesPerPage     = 100
+)
+
+// ReleaseAsset 发布附件
+type ReleaseAsset struct {
+	Name               string `json:"name"`
+	Size               int64  `json:"size"`
+	BrowserDownloadURL string `json:"browser_download_url"`
+}
+
+// Version 返回去掉 'v' 前缀的版本号
+func (r *Release) Version() string {
+	return strings.TrimPrefix(r.TagName, "v")
+}
+
+// Date 返回发布日期，Gitee 没有 published_at 字段时使用 created_at
+func (r *Release) Date() string {
+	date := r.PublishedAt
+	if date == "" {
+		date = r.CreatedAt
+	}
+	if len(date) > 10 {
+		date = date[:10]
+	}
+	return date
+}
+
+// versionPattern frp 的版本号格式，如 0.61.1、0.52.0-beta
+var versionPattern = regexp.MustCompile(`^\d+\.\d+\.\d+(-[0-9A-Za-z.]+)?$`)
+
+// normalizeVersion 去掉版本号的 'v' 前缀并检查格式
+func normalizeVersion(version string) (string, error) {
+	version = strings.TrimPrefix(strings.TrimSpace(version), "v")
+	if !versionPattern.MatchString(version) {
+		return "", fmt.Errorf("无效的版本号: %s，应为 0.61.1 这样的格式", version)
+	}
+	return version, nil
+}
+
+// fetchReleases 分页获取下载源的版本列表，按发布时间倒序排列
+func fetchReleases(ctx context.Context, mirror *Mirror, page, perPage int) ([]Release, error) {
+	url := fmt.Sprintf("%s?page=%d&per_page=%d", mirror.releasesAPI(), page, perPage)
+	if mirror.isGitee() {
+		// Gitee 默认按时间正序返回
+		url += "&direction=desc"
+	}
+
+	resp, err := httpGet(ctx, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var releases []Release
+	if err := json.NewDecoder(resp.Body).Decode(&releases); err != nil {
+		return nil, err
+	}
+
+	// 草稿版本无法下载
+	stable := releases[:0]
+	for _, release := range releases {
+		if !release.Draft {
+			stable = append(stable, release)
+		}
+	}
+	return stable, nil
+}
+
+// resolveVersion 确定要安装的版本：指定了 --version 时直接使用，否则获取最新版本
+func (fm *FrpsManager) resolveVersion(ctx context.Context, mirror *Mirror, opts *DownloadOptions) error {
+	if opts.Version != "" {
+		fm.SystemInfo.FrpsVersion = opts.Version
+		fm.Colors["green"].Printf("使用指定版本: %s\n", opts.Version)
+		return nil
+	}
+
+	// 自动选择下载源时已经确认了最新版本
+	if opts.probedVersion != "" {
+		fm.SystemInfo.FrpsVersion = opts.probedVersion
+		fm.Colors["green"].Printf("找到最新版本: %s\n", opts.probedVersion)
+		return nil
+	}
+
+	if !opts.Prerelease {
+		return fm.getLatestVersion(ctx, mirror)
+	}
+
+	// releases/latest 接口不包含预发布版本，从版本列表中取最新的一个
+	fm.Colors["green"].Println("正在获取最新版本 (包含预发布版本)...")
+	releases, err := fetchReleases(ctx, mirror, 1, DefaultReleasesPerPage)
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 {
+		return fmt.Errorf("下载源没有可用的版本")
+	}
+
+	fm.SystemInfo.FrpsVersion = releases[0].Version()
+	fm.Colors["green"].Printf("找到最新版本: %s\n", fm.SystemInfo.FrpsVersion)
+	return nil
+}
+
+// versionTarget 返回要安装的版本的描述，用于 resolveVersion 失败时的提示
+func versionTarget(opts *DownloadOptions) string {
+	if opts.Version != "" {
+		return "版本 " + opts.Version
+	}
+	return "最新版本"
+}
+
+// ListVersions 列出下载源的可用版本
+func (fm *FrpsManager) ListVersions(ctx context.Context, opts *VersionsOptions) {
+	configureNetwork(opts.NetworkOptions)
+
+	mirrors, err := loadMirrors(&opts.DownloadOptions)
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	mirror := mirrors[0]
+	if strings.EqualFold(opts.Source, AutoSource) {
+		if mirror, err = fm.autoSelectMirror(ctx, mirrors, &opts.DownloadOptions); err != nil {
+			fm.Colors["red"].Printf("%v\n", err)
+			return
+		}
+	} else if opts.Source != "" {
+		if mirror, err = findMirror(mirrors, opts.Source); err != nil {
+			fm.Colors["red"].Printf("%v\n", err)
+			return
+		}
+	}
+
+	releases, err := fetchReleases(ctx, mirror, opts.Page, opts.PerPage)
+	if err != nil {
+		fm.Colors["red"].Printf("获取版本列表失败: %v\n", err)
+		return
+	}
+
+	fmt.Printf("============== frps 可用版本 (%s，第 %d 页) ==============\n", mirror.Name, opts.Page)
+
+	shown := 0
+	for _, release := range releases {
+		if release.Prerelease && !opts.Prerelease {
+			continue
+		}
+		mark := ""
+		if release.Prerelease {
+			mark = fm.Colors["yellow"].Sprint("  预发布")
+		}
+		fmt.Printf("  %-16s %s%s\n", release.Version(), release.Date(), mark)
+		shown++
+	}
+
+	if shown == 0 {
+		fm.Colors["yellow"].Println("  没有更多版本")
+	}
+	if len(releases) == opts.PerPage {
+		next := fmt.Sprintf("frps-onekey versions --download-source %s --page %d --per-page %d", mirror.Name, opts.Page+1, opts.PerPage)
+		if opts.Prerelease {
+			next += " --prerelease"
+		}
+		fmt.Printf("\n查看下一页: %s\n", next)
+	}
+	fmt.Println("安装指定版本: frps-onekey install --version <版本号>")
+}
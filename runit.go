@@ -0,0 +1,130 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// runitManager 通过 runsvdir 监管 frps 或 frpc，适用于 Void Linux 及以 runit 为 PID 1 的容器
+type runitManager struct {
+	fm *FrpsManager
+}
+
+// superviseRunScript 生成 runit/s6 通用的 run 脚本
+func superviseRunScript(c *Component) string {
+	return fmt.Sprintf(`#!/bin/sh
+exec 2>&1
+cd %s
+exec %s
+`, c.Dir, c.ServiceCommand())
+}
+
+// findScanDir 返回第一个存在的监管扫描目录
+func findScanDir(candidates []string) string {
+	for _, dir := range candidates {
+		if stat, err := os.Stat(dir); err == nil && stat.IsDir() {
+			return dir
+		}
+	}
+	return candidates[0]
+}
+
+// waitForSupervisor 等待监管进程接管服务目录
+func waitForSupervisor(serviceDir string) error {
+	okFile := filepath.Join(serviceDir, "supervise", "ok")
+	for i := 0; i < 20; i++ {
+		if fileExists(okFile) {
+			return nil
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return fmt.Errorf("等待监管进程接管 %s 超时", serviceDir)
+}
+
+// scanDir 返回 runsvdir 扫描的目录
+func (r *runitManager) scanDir() string {
+	return findScanDir([]string{"/var/service", "/etc/service", "/service", "/etc/runit/runsvdir/default"})
+}
+
+// serviceDir 返回服务定义目录
+func (r *runitManager) serviceDir() string {
+	return "/etc/sv/" + r.fm.Component.Name
+}
+
+// linkPath 返回服务在扫描目录中的链接
+func (r *runitManager) linkPath() string {
+	return filepath.Join(r.scanDir(), r.fm.Component.Name)
+}
+
+func (r *runitManager) Name() string {
+	return "runit"
+}
+
+func (r *runitManager) Installed() bool {
+	return fileExists(r.serviceDir())
+}
+
+func (r *runitManager) Install(ctx context.Context) error {
+	r.fm.Colors["green"].Printf("正在写入 runit 服务目录 %s...\n", r.serviceDir())
+	return writeServiceFile(filepath.Join(r.serviceDir(), "run"), superviseRunScript(r.fm.Component), 0755)
+}
+
+func (r *runitManager) Enable() error {
+	link := r.linkPath()
+	if fileExists(link) {
+		return nil
+	}
+	if err := os.Symlink(r.serviceDir(), link); err != nil {
+		return fmt.Errorf("创建软链接 %s 失败: %v", link, err)
+	}
+	return waitForSupervisor(link)
+}
+
+func (r *runitManager) Start() error {
+	_, err := runCommand("sv", "up", r.linkPath())
+	return err
+}
+
+func (r *runitManager) Stop() error {
+	_, err := runCommand("sv", "down", r.linkPath())
+	return err
+}
+
+func (r *runitManager) Restart() error {
+	_, err := runCommand("sv", "restart", r.linkPath())
+	return err
+}
+
+func (r *runitManager) Status() (string, error) {
+	output, err := exec.Command("sv", "status", r.linkPath()).CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+func (r *runitManager) Remove() error {
+	link := r.linkPath()
+	if fileExists(link) {
+		runCommand("sv", "down", link)
+		if err := os.Remove(link); err != nil {
+			return fmt.Errorf("删除软链接 %s 失败: %v", link, err)
+		}
+	}
+	if err := os.RemoveAll(r.serviceDir()); err != nil {
+		return fmt.Errorf("删除 %s 失败: %v", r.serviceDir(), err)
+	}
+	return nil
+}
+
+func (r *runitManager) ControlCommand(action string) string {
+	switch action {
+	case "start":
+		action = "up"
+	case "stop":
+		action = "down"
+	}
+	return fmt.Sprintf("sv %s %s", action, r.linkPath())
+}
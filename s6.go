@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// s6Manager 通过 s6-svscan 监管 frps 或 frpc，适用于以 s6 为 PID 1 的容器
+type s6Manager struct {
+	fm *FrpsManager
+}
+
+// scanDir 返回 s6-svscan 扫描的目录
+func (s *s6Manager) scanDir() string {
+	return findScanDir([]string{"/run/service", "/service", "/etc/s6/service", "/var/service"})
+}
+
+// serviceDir 返回服务定义目录
+func (s *s6Manager) serviceDir() string {
+	return "/etc/s6/sv/" + s.fm.Component.Name
+}
+
+// linkPath 返回服务在扫描目录中的链接
+func (s *s6Manager) linkPath() string {
+	return filepath.Join(s.scanDir(), s.fm.Component.Name)
+}
+
+func (s *s6Manager) Name() string {
+	return "s6"
+}
+
+func (s *s6Manager) Installed() bool {
+	return fileExists(s.serviceDir())
+}
+
+func (s *s6Manager) Install(ctx context.Context) error {
+	s.fm.Colors["green"].Printf("正在写入 s6 服务目录 %s...\n", s.serviceDir())
+	return writeServiceFile(filepath.Join(s.serviceDir(), "run"), superviseRunScript(s.fm.Component), 0755)
+}
+
+func (s *s6Manager) Enable() error {
+	link := s.linkPath()
+	if !fileExists(link) {
+		if err := os.Symlink(s.serviceDir(), link); err != nil {
+			return fmt.Errorf("创建软链接 %s 失败: %v", link, err)
+		}
+	}
+	// 通知 s6-svscan 重新扫描服务目录
+	if _, err := runCommand("s6-svscanctl", "-a", s.scanDir()); err != nil {
+		return err
+	}
+	return waitForSupervisor(link)
+}
+
+func (s *s6Manager) Start() error {
+	_, err := runCommand("s6-svc", "-u", s.linkPath())
+	return err
+}
+
+func (s *s6Manager) Stop() error {
+	_, err := runCommand("s6-svc", "-d", s.linkPath())
+	return err
+}
+
+func (s *s6Manager) Restart() error {
+	// 先确保服务处于 up 状态，再发送 SIGTERM 由 s6-supervise 重新拉起
+	if _, err := runCommand("s6-svc", "-u", s.linkPath()); err != nil {
+		return err
+	}
+	_, err := runCommand("s6-svc", "-t", s.linkPath())
+	return err
+}
+
+func (s *s6Manager) Status() (string, error) {
+	output, err := exec.Command("s6-svstat", s.linkPath()).CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+func (s *s6Manager) Remove() error {
+	link := s.linkPath()
+	if fileExists(link) {
+		runCommand("s6-svc", "-d", link)
+		if err := os.Remove(link); err != nil {
+			return fmt.Errorf("删除软链接 %s 失败: %v", link, err)
+		}
+		runCommand("s6-svscanctl", "-an", s.scanDir())
+	}
+	if err := os.RemoveAll(s.serviceDir()); err != nil {
+		return fmt.Errorf("删除 %s 失败: %v", s.serviceDir(), err)
+	}
+	return nil
+}
+
+func (s *s6Manager) ControlCommand(action string) string {
+	flags := map[string]string{"start": "-u", "stop": "-d", "restart": "-t"}
+	if action == "status" {
+		return fmt.Sprintf("s6-svstat %s", s.linkPath())
+	}
+	return fmt.Sprintf("s6-svc %s %s", flags[action], s.linkPath())
+}
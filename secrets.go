@@ -0,0 +1,357 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// secretKeys 可以移到环境变量文件中的配置项及对应的环境变量名
+var secretKeys = []struct {
+	key string
+	env string
+}{
+	{"auth.token", "FRPS_AUTH_TOKEN"},
+	{"webServer.password", "FRPS_DASHBOARD_PASSWORD"},
+}
+
+// envTemplateRe 匹配 frp 的环境变量模板 {{ .Envs.NAME }}
+var envTemplateRe = regexp.MustCompile(`\{\{\s*\.Envs\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// envNameRe 合法的环境变量名
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// EnvFile 返回服务加载的环境变量文件路径
+func (c *Component) EnvFile() string {
+	return filepath.Join(c.Dir, c.Name+".env")
+}
+
+// ServiceCommand 返回服务管理器启动程序的命令行，存在环境变量文件时通过 sh 先加载该文件
+func (c *Component) ServiceCommand() string {
+	if !fileExists(c.EnvFile()) {
+		return c.CommandLine()
+	}
+	return fmt.Sprintf("/bin/sh -c 'set -a; . %s; exec %s'", c.EnvFile(), c.CommandLine())
+}
+
+// usesSecretsEnv 配置中是否有敏感配置项引用了环境变量
+func usesSecretsEnv(content []byte) bool {
+	doc := parseTOMLDoc(content)
+	for _, s := range secretKeys {
+		if i := doc.lookup(s.key); i >= 0 && envTemplateRe.MatchString(doc.valueText(i)) {
+			return true
+		}
+	}
+	return false
+}
+
+// envTemplate 返回引用环境变量的模板
+func envTemplate(name string) string {
+	return "{{ .Envs." + name + " }}"
+}
+
+// secretEnvName 按配置项或环境变量名查找敏感配置项对应的环境变量名
+func secretEnvName(name string) (key, env string, ok bool) {
+	for _, s := range secretKeys {
+		if s.key == name || s.env == name {
+			return s.key, s.env, true
+		}
+	}
+	return "", "", false
+}
+
+// validateEnvValue 检查值能否写入环境变量文件。文件同时由 sh 与 systemd 读取，值以单引号括起；
+// frps 将模板原样替换到 TOML 字符串中，值也不能包含双引号与反斜杠
+func validateEnvValue(value string) error {
+	if value == "" {
+		return fmt.Errorf("不能为空")
+	}
+	if strings.ContainsAny(value, "'\"\\\r\n") {
+		return fmt.Errorf("不能包含引号、反斜杠或换行符")
+	}
+	return nil
+}
+
+// readEnvFile 读取环境变量文件，文件不存在时返回空
+func readEnvFile(path string) (map[string]string, error) {
+	env := make(map[string]string)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return env, nil
+		}
+		return nil, err
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name, value, ok := strings.Cut(strings.TrimPrefix(line, "export "), "=")
+		if !ok || !envNameRe.MatchString(name) {
+			return nil, fmt.Errorf("%s 第 %d 行格式错误，应为 NAME=value", path, n)
+		}
+		if len(value) >= 2 && (value[0] == '\'' || value[0] == '"') && value[len(value)-1] == value[0] {
+			value = value[1 : len(value)-1]
+		}
+		env[name] = value
+	}
+	return env, scanner.Err()
+}
+
+// writeEnvFile 写入环境变量文件，只允许服务用户读取
+func writeEnvFile(path string, env map[string]string) error {
+	names := make([]string, 0, len(env))
+	for name := range env {
+		if err := validateEnvValue(env[name]); err != nil {
+			return fmt.Errorf("%s %v", name, err)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# 由 frps-onekey 管理，配置文件通过 {{ .Envs.NAME }} 引用，使用 'frps-onekey secrets set' 修改\n")
+	for _, name := range names {
+		fmt.Fprintf(&b, "%s='%s'\n", name, env[name])
+	}
+	return writeFileAtomic(path, []byte(b.String()), secretFileMode)
+}
+
+// configEnv 返回渲染配置模板时可用的环境变量：当前进程的环境变量，由环境变量文件覆盖
+func (c *Component) configEnv() (map[string]string, error) {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if name, value, ok := strings.Cut(kv, "="); ok {
+			env[name] = value
+		}
+	}
+	fileEnv, err := readEnvFile(c.EnvFile())
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range fileEnv {
+		env[name] = value
+	}
+	return env, nil
+}
+
+// renderEnvTemplates 将配置中的 {{ .Envs.NAME }} 替换为环境变量的值，与 frps 加载配置时一致，未设置的变量逐个报告
+func renderEnvTemplates(content []byte, env map[string]string) ([]byte, ConfigIssues) {
+	var issues ConfigIssues
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		lines[i] = envTemplateRe.ReplaceAllStringFunc(line, func(match string) string {
+			name := envTemplateRe.FindStringSubmatch(match)[1]
+			value, ok := env[name]
+			if !ok {
+				column := len([]rune(line[:strings.Index(line, match)])) + 1
+				issues = append(issues, ConfigIssue{Line: i + 1, Column: column, Message: fmt.Sprintf("环境变量 %s 未设置", name)})
+			}
+			return value
+		})
+	}
+	return []byte(strings.Join(lines, "\n")), issues
+}
+
+// renderConfig 按组件的环境变量渲染配置内容
+func (c *Component) renderConfig(content []byte) ([]byte, error) {
+	env, err := c.configEnv()
+	if err != nil {
+		return nil, err
+	}
+	rendered, issues := renderEnvTemplates(content, env)
+	if len(issues) > 0 {
+		return nil, issues
+	}
+	return rendered, nil
+}
+
+// externalizeSecrets 将配置中的敏感配置项移到 env 中，配置改为引用环境变量，已经是模板的配置项保持不变
+func externalizeSecrets(content []byte, env map[string]string) ([]byte, error) {
+	doc := parseTOMLDoc(content)
+	for _, s := range secretKeys {
+		i := doc.lookup(s.key)
+		if i < 0 {
+			continue
+		}
+		text := doc.valueText(i)
+		if envTemplateRe.MatchString(text) {
+			continue
+		}
+		value, err := decodeTOMLValue(text)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", s.key, err)
+		}
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s: 类型错误，应为字符串", s.key)
+		}
+		if err := validateEnvValue(str); err != nil {
+			return nil, fmt.Errorf("%s %v", s.key, err)
+		}
+		env[s.env] = str
+		doc.set(s.key, tomlString(envTemplate(s.env)))
+	}
+	return doc.Bytes(), nil
+}
+
+// moveSecretsToEnv 将配置内容中的敏感配置项写入环境变量文件，返回改为引用环境变量的配置内容。
+// 环境变量文件先于配置文件写入，服务不会读到引用了未设置变量的配置
+func (fm *FrpsManager) moveSecretsToEnv(content []byte) ([]byte, error) {
+	envFile := fm.Component.EnvFile()
+	env, err := readEnvFile(envFile)
+	if err != nil {
+		return nil, err
+	}
+	created := !fileExists(envFile)
+	content, err = externalizeSecrets(content, env)
+	if err != nil {
+		return nil, err
+	}
+	if err := writeEnvFile(envFile, env); err != nil {
+		return nil, fmt.Errorf("写入 %s 失败: %v", envFile, err)
+	}
+	fm.Colors["green"].Printf("✓ token 与 Dashboard 密码已移到 %s\n", envFile)
+	if created {
+		fm.refreshService()
+	}
+	return content, nil
+}
+
+// refreshService 重新写入已安装的服务定义，使其加载新创建的环境变量文件
+func (fm *FrpsManager) refreshService() {
+	if !fm.Service.Installed() {
+		return
+	}
+	if err := fm.Service.Install(context.Background()); err != nil {
+		fm.Colors["yellow"].Printf("警告：更新服务定义失败: %v\n", err)
+		return
+	}
+	if err := fm.Service.Enable(); err != nil {
+		fm.Colors["yellow"].Printf("警告：更新服务定义失败: %v\n", err)
+	}
+}
+
+// SecretsShow 显示环境变量文件中的敏感配置项，以及配置文件是否引用了它们
+func (fm *FrpsManager) SecretsShow() {
+	if !fm.checkRoot() {
+		return
+	}
+	envFile := fm.Component.EnvFile()
+	env, err := readEnvFile(envFile)
+	if err != nil {
+		fm.Colors["red"].Printf("读取 %s 失败: %v\n", envFile, err)
+		return
+	}
+	var doc *tomlDoc
+	if content, err := os.ReadFile(fm.Component.ConfigPath()); err == nil {
+		doc = parseTOMLDoc(content)
+	}
+
+	fmt.Printf("============== 环境变量文件 (%s) ==============\n", envFile)
+	if !fileExists(envFile) {
+		fm.Colors["yellow"].Println("  环境变量文件不存在，token 与 Dashboard 密码保存在配置文件中")
+		fmt.Printf("  使用 '%s secrets set <配置项> [<值>]' 或 import-config --secrets-env 移出配置文件\n", fm.Component.Command)
+		return
+	}
+	for _, s := range secretKeys {
+		value, ok := env[s.env]
+		if !ok {
+			value = "(未设置)"
+		}
+		referenced := "否"
+		if doc != nil {
+			if i := doc.lookup(s.key); i >= 0 && strings.Contains(doc.lines[i], envTemplate(s.env)) {
+				referenced = "是"
+			}
+		}
+		fmt.Printf("  %-18s %-24s %s  (配置文件引用: %s)\n", s.key, s.env, value, referenced)
+	}
+	for _, name := range sortedKeys(env) {
+		if _, _, ok := secretEnvName(name); !ok {
+			fmt.Printf("  %-18s %-24s %s\n", "", name, env[name])
+		}
+	}
+}
+
+// SecretsSet 设置敏感配置项的值并写入环境变量文件，配置文件尚未引用时改为引用环境变量，value 为空时随机生成
+func (fm *FrpsManager) SecretsSet(name, value string) {
+	if !fm.checkRoot() {
+		return
+	}
+	key, envName, ok := secretEnvName(name)
+	if !ok {
+		names := make([]string, 0, len(secretKeys))
+		for _, s := range secretKeys {
+			names = append(names, s.key)
+		}
+		fm.Colors["red"].Printf("不支持的配置项 %s (可选 %s)\n", name, strings.Join(names, ", "))
+		return
+	}
+	if value == "" {
+		value = fm.generateRandomString(16)
+		fm.Colors["yellow"].Printf("已生成 %s: %s\n", key, value)
+	}
+	if err := validateEnvValue(value); err != nil {
+		fm.Colors["red"].Printf("%s %v\n", key, err)
+		return
+	}
+
+	doc, _, err := fm.readConfigDoc()
+	if err != nil {
+		fm.Colors["red"].Printf("%v\n", err)
+		return
+	}
+	envFile := fm.Component.EnvFile()
+	env, err := readEnvFile(envFile)
+	if err != nil {
+		fm.Colors["red"].Printf("读取 %s 失败: %v\n", envFile, err)
+		return
+	}
+	created := !fileExists(envFile)
+	env[envName] = value
+	if err := writeEnvFile(envFile, env); err != nil {
+		fm.Colors["red"].Printf("写入 %s 失败: %v\n", envFile, err)
+		return
+	}
+	fm.Colors["green"].Printf("✓ 已将 %s 写入 %s\n", envName, envFile)
+	if created {
+		fm.refreshService()
+	}
+
+	// 配置文件尚未引用环境变量时改为引用，同时校验配置
+	template := tomlString(envTemplate(envName))
+	if i := doc.lookup(key); i < 0 || !strings.Contains(doc.lines[i], envTemplate(envName)) {
+		doc.set(key, template)
+		if err := fm.applyConfigChange(doc.Bytes(), "secrets set "+key); err != nil {
+			fm.Colors["red"].Printf("%v\n", err)
+		}
+		return
+	}
+	if fm.confirm(fmt.Sprintf("是否重启 %s 服务以应用新配置？(y/n): ", fm.Component.Name)) {
+		if fm.isInstalled() {
+			fm.Restart()
+		} else {
+			fm.Colors["yellow"].Printf("%s 服务未运行，请使用 '%s start' 启动服务\n", fm.Component.Name, fm.Component.Command)
+		}
+	}
+}
+
+// sortedKeys 返回 map 中已排序的键
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
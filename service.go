@@ -1,15 +1,16 @@
 package main
 
 import (
+	"context"
 	"bufio"
+	"bytes"
 	"fmt"
 	"os"
 	"os/exec"
-	"path/filepath"
 	"strings"
 )
 
-// Start 启动 frps 服务
+// Start 启动当前组件的服务
 func (fm *FrpsManager) Start() {
 	if !fm.checkRoot() {
 		return
@@ -18,24 +19,23 @@ func (fm *FrpsManager) Start() {
 	fm.showBanner()
 	
 	if fm.isInstalled() {
-		fm.Colors["yellow"].Println("frps 服务已经在运行中。")
+		fm.Colors["yellow"].Printf("%s 服务已经在运行中。\n", fm.Component.Name)
 		return
 	}
 
-	cmd := exec.Command(InitScript, "start")
-	if err := cmd.Run(); err != nil {
+	if err := fm.Service.Start(); err != nil {
 		fm.Colors["red"].Printf("启动服务失败: %v\n", err)
 		return
 	}
 
 	if fm.isInstalled() {
-		fm.Colors["green"].Println("frps 服务启动成功。")
+		fm.Colors["green"].Printf("%s 服务启动成功。\n", fm.Component.Name)
 	} else {
-		fm.Colors["red"].Println("frps 服务启动失败。")
+		fm.Colors["red"].Printf("%s 服务启动失败。\n", fm.Component.Name)
 	}
 }
 
-// Stop 停止 frps 服务
+// Stop 停止当前组件的服务
 func (fm *FrpsManager) Stop() {
 	if !fm.checkRoot() {
 		return
@@ -44,24 +44,23 @@ func (fm *FrpsManager) Stop() {
 	fm.showBanner()
 	
 	if !fm.isInstalled() {
-		fm.Colors["yellow"].Println("frps 服务没有运行。")
+		fm.Colors["yellow"].Printf("%s 服务没有运行。\n", fm.Component.Name)
 		return
 	}
 
-	cmd := exec.Command(InitScript, "stop")
-	if err := cmd.Run(); err != nil {
+	if err := fm.Service.Stop(); err != nil {
 		fm.Colors["red"].Printf("停止服务失败: %v\n", err)
 		return
 	}
 
 	if !fm.isInstalled() {
-		fm.Colors["green"].Println("frps 服务停止成功。")
+		fm.Colors["green"].Printf("%s 服务停止成功。\n", fm.Component.Name)
 	} else {
-		fm.Colors["red"].Println("frps 服务停止失败。")
+		fm.Colors["red"].Printf("%s 服务停止失败。\n", fm.Component.Name)
 	}
 }
 
-// Restart 重启 frps 服务
+// Restart 重启当前组件的服务
 func (fm *FrpsManager) Restart() {
 	if !fm.checkRoot() {
 		return
@@ -69,51 +68,55 @@ func (fm *FrpsManager) Restart() {
 
 	fm.showBanner()
 
-	cmd := exec.Command(InitScript, "restart")
-	if err := cmd.Run(); err != nil {
+	if err := fm.Service.Restart(); err != nil {
 		fm.Colors["red"].Printf("重启服务失败: %v\n", err)
 		return
 	}
 
 	if fm.isInstalled() {
-		fm.Colors["green"].Println("frps 服务重启成功。")
+		fm.Colors["green"].Printf("%s 服务重启成功。\n", fm.Component.Name)
 	} else {
-		fm.Colors["red"].Println("frps 服务重启失败。")
+		fm.Colors["red"].Printf("%s 服务重启失败。\n", fm.Component.Name)
 	}
 }
 
-// Status 查看 frps 服务状态
+// Status 查看当前组件的服务状态
 func (fm *FrpsManager) Status() {
 	fm.showBanner()
 
 	if fm.isInstalled() {
-		fm.Colors["green"].Println("frps 服务正在运行。")
+		fm.Colors["green"].Printf("%s 服务正在运行。\n", fm.Component.Name)
+		
+		// 显示服务管理器给出的状态
+		if status, _ := fm.Service.Status(); status != "" {
+			fm.Colors["blue"].Printf("服务管理器: %s\n", fm.Service.Name())
+			fmt.Println(status)
+		}
 		
 		// 显示进程信息
-		cmd := exec.Command("ps", "aux")
-		output, err := cmd.Output()
-		if err == nil {
-			lines := strings.Split(string(output), "\n")
-			for _, line := range lines {
-				if strings.Contains(line, ProgramName) && !strings.Contains(line, "grep") {
-					fmt.Printf("进程信息: %s\n", line)
-				}
+		if procs, err := findProcesses(fm.Component.Name); err == nil {
+			for _, proc := range procs {
+				fmt.Printf("进程信息: PID %d, 内存 %s, 命令 %s\n",
+					proc.PID, fm.formatBytes(proc.RSS), strings.Join(proc.Cmdline, " "))
 			}
 		}
 		
 		// 显示配置文件路径
-		configPath := filepath.Join(ProgramDir, ConfigFile)
+		configPath := fm.Component.ConfigPath()
 		if _, err := os.Stat(configPath); err == nil {
 			fm.Colors["blue"].Printf("配置文件: %s\n", configPath)
+			if fm.Component == ServerComponent && fm.loadInstalledConfig() {
+				fm.showConfigSummary()
+			}
 		}
 		
 		// 显示日志文件路径
-		logPath := filepath.Join(ProgramDir, "frps.log")
+		logPath := fm.Component.LogPath()
 		if _, err := os.Stat(logPath); err == nil {
 			fm.Colors["blue"].Printf("日志文件: %s\n", logPath)
 		}
 	} else {
-		fm.Colors["red"].Println("frps 服务没有运行。")
+		fm.Colors["red"].Printf("%s 服务没有运行。\n", fm.Component.Name)
 	}
 }
 
@@ -123,7 +126,7 @@ func (fm *FrpsManager) ConfigEdit() {
 		return
 	}
 
-	configPath := filepath.Join(ProgramDir, ConfigFile)
+	configPath := fm.Component.ConfigPath()
 	
 	if _, err := os.Stat(configPath); os.IsNotExist(err) {
 		fm.Colors["red"].Println("配置文件不存在！")
@@ -170,25 +173,56 @@ func (fm *FrpsManager) ConfigEdit() {
 	}
 	fmt.Println()
 
-	cmd := exec.Command(editor, configPath)
-	cmd.Stdin = os.Stdin
-	cmd.Stdout = os.Stdout
-	cmd.Stderr = os.Stderr
-
-	if err := cmd.Run(); err != nil {
-		fm.Colors["red"].Printf("编辑配置文件失败: %v\n", err)
+	// 编辑同目录的临时副本，校验通过后才替换配置文件，编辑出错的配置不会被正在运行的服务读到
+	original, err := os.ReadFile(configPath)
+	if err != nil {
+		fm.Colors["red"].Printf("读取配置文件失败: %v\n", err)
+		return
+	}
+	tmp, err := createTempFile(configPath, secretFileMode, func(f *os.File) error {
+		_, err := f.Write(original)
+		return err
+	})
+	if err != nil {
+		fm.Colors["red"].Printf("创建临时文件失败: %v\n", err)
 		return
 	}
+	defer os.Remove(tmp)
 
-	fm.Colors["green"].Println("配置文件编辑完成。")
-	fmt.Print("是否重启 frps 服务以应用新配置？(y/n): ")
-	
-	reader := bufio.NewReader(os.Stdin)
-	choice, _ := reader.ReadString('\n')
-	choice = strings.TrimSpace(strings.ToLower(choice))
-	
-	if choice == "y" || choice == "yes" {
-		fm.Restart()
+	command := "config"
+	if fm.Component == ClientComponent {
+		command = "client config"
+	}
+	for {
+		cmd := exec.Command(editor, tmp)
+		cmd.Stdin = os.Stdin
+		cmd.Stdout = os.Stdout
+		cmd.Stderr = os.Stderr
+		if err := cmd.Run(); err != nil {
+			fm.Colors["red"].Printf("编辑配置文件失败: %v\n", err)
+			return
+		}
+
+		content, err := os.ReadFile(tmp)
+		if err != nil {
+			fm.Colors["red"].Printf("读取编辑后的配置失败: %v\n", err)
+			return
+		}
+		if bytes.Equal(content, original) {
+			fm.Colors["yellow"].Println("配置文件未修改。")
+			return
+		}
+
+		// 校验失败时可以继续编辑，非交互模式下直接放弃修改
+		err = fm.applyConfigChange(content, command)
+		if err == nil {
+			return
+		}
+		fm.Colors["red"].Printf("%v\n", err)
+		if fm.NonInteractive || !fm.confirm("是否重新编辑？(y/n): ") {
+			fm.Colors["yellow"].Println("已放弃修改。")
+			return
+		}
 	}
 }
 
@@ -198,21 +232,26 @@ func (fm *FrpsManager) ShowVersion() {
 	
 	fmt.Printf("frps-onekey 版本: %s\n", Version)
 	
-	// 显示 frps 二进制版本
-	binaryPath := filepath.Join(ProgramDir, ProgramName)
+	// 显示当前组件的二进制版本
+	binaryPath := fm.Component.BinaryPath()
 	if _, err := os.Stat(binaryPath); err == nil {
 		cmd := exec.Command(binaryPath, "--version")
 		output, err := cmd.Output()
 		if err == nil {
-			fmt.Printf("frps 版本: %s", string(output))
+			fmt.Printf("%s 版本: %s", fm.Component.Name, string(output))
 		}
 	}
 	
 	fmt.Printf("系统架构: %s\n", fm.SystemInfo.FrpsArch)
-	fmt.Printf("操作系统: %s\n", fm.SystemInfo.OS)
+	fmt.Printf("操作系统: %s\n", fm.SystemInfo.DisplayName())
+
+	if fm.Component == ServerComponent && fm.loadInstalledConfig() {
+		fmt.Printf("配置文件: %s\n", fm.Component.ConfigPath())
+		fmt.Printf("绑定端口: %d\n", fm.Config.BindPort)
+	}
 }
 
-// Uninstall 卸载 frps
+// Uninstall 卸载当前组件
 func (fm *FrpsManager) Uninstall() {
 	if !fm.checkRoot() {
 		return
@@ -221,56 +260,48 @@ func (fm *FrpsManager) Uninstall() {
 	fm.showBanner()
 	
 	// 检查是否已安装
-	binaryPath := filepath.Join(ProgramDir, ProgramName)
+	binaryPath := fm.Component.BinaryPath()
 	
-	_, err1 := os.Stat(InitScript)
-	_, err2 := os.Stat(binaryPath)
-	if os.IsNotExist(err1) && os.IsNotExist(err2) {
-		fm.Colors["yellow"].Println("frps 没有安装。")
+	_, err := os.Stat(binaryPath)
+	if !fm.Service.Installed() && os.IsNotExist(err) {
+		fm.Colors["yellow"].Printf("%s 没有安装。\n", fm.Component.Name)
 		return
 	}
 
-	fmt.Println("============== 卸载 frps ==============")
-	fm.Colors["yellow"].Print("您确定要卸载吗？")
-	fmt.Print("[Y/N]: ")
-	
-	reader := bufio.NewReader(os.Stdin)
-	choice, _ := reader.ReadString('\n')
-	choice = strings.TrimSpace(strings.ToLower(choice))
-	
-	if choice != "y" && choice != "yes" {
-		fmt.Println("您选择了 [No]，脚本退出！")
-		return
-	}
+	fmt.Printf("============== 卸载 %s ==============\n", fm.Component.Name)
+	if !fm.NonInteractive {
+		fm.Colors["yellow"].Print("您确定要卸载吗？")
+		fmt.Print("[Y/N]: ")
+		
+		reader := bufio.NewReader(os.Stdin)
+		choice, _ := reader.ReadString('\n')
+		choice = strings.TrimSpace(strings.ToLower(choice))
+		
+		if choice != "y" && choice != "yes" {
+			fmt.Println("您选择了 [No]，脚本退出！")
+			return
+		}
 
-	fmt.Println()
-	fmt.Println("您选择了 [Yes]，按任意键继续。")
-	reader.ReadString('\n')
+		fmt.Println()
+		fmt.Println("您选择了 [Yes]，按任意键继续。")
+		reader.ReadString('\n')
+	}
 
 	// 停止服务
 	if fm.isInstalled() {
-		fm.Colors["green"].Println("正在停止 frps 服务...")
-		cmd := exec.Command(InitScript, "stop")
-		cmd.Run()
+		fm.Colors["green"].Printf("正在停止 %s 服务...\n", fm.Component.Name)
+		fm.Service.Stop()
 	}
 
 	// 移除服务
-	fm.Colors["green"].Println("正在移除服务...")
-	switch fm.SystemInfo.OS {
-	case "CentOS", "RHEL", "Rocky", "AlmaLinux":
-		cmd := exec.Command("chkconfig", "--del", ProgramName)
-		cmd.Run()
-	case "Ubuntu", "Debian":
-		cmd := exec.Command("update-rc.d", "-f", ProgramName, "remove")
-		cmd.Run()
+	fm.Colors["green"].Printf("正在移除服务 (%s)...\n", fm.Service.Name())
+	if err := fm.Service.Remove(); err != nil {
+		fm.Colors["yellow"].Printf("移除服务失败: %v\n", err)
 	}
 
 	// 删除文件
 	filesToRemove := []string{
-		InitScript,
-		"/var/run/" + ProgramName + ".pid",
-		"/usr/bin/" + ProgramName,
-		ProgramDir,
+		fm.Component.Dir,
 	}
 
 	for _, file := range filesToRemove {
@@ -281,25 +312,26 @@ func (fm *FrpsManager) Uninstall() {
 		}
 	}
 
-	fm.Colors["green"].Println("frps 卸载成功！")
+	fm.Colors["green"].Printf("%s 卸载成功！\n", fm.Component.Name)
 }
 
-// Update 更新 frps
-func (fm *FrpsManager) Update() {
+// Update 更新当前组件
+func (fm *FrpsManager) Update(ctx context.Context, opts *UpdateOptions) {
 	if !fm.checkRoot() {
 		return
 	}
+	configureNetwork(opts.NetworkOptions)
 
 	fm.showBanner()
 	
 	// 检查是否已安装
-	binaryPath := filepath.Join(ProgramDir, ProgramName)
+	binaryPath := fm.Component.BinaryPath()
 	if _, err := os.Stat(binaryPath); os.IsNotExist(err) {
-		fm.Colors["red"].Println("frps 没有安装，请先安装！")
+		fm.Colors["red"].Printf("%s 没有安装，请先安装！\n", fm.Component.Name)
 		return
 	}
 
-	fmt.Println("============== 更新 frps ==============")
+	fmt.Printf("============== 更新 %s ==============\n", fm.Component.Name)
 	
 	// 获取当前版本
 	cmd := exec.Command(binaryPath, "--version")
@@ -310,54 +342,73 @@ func (fm *FrpsManager) Update() {
 	}
 	currentVersion := strings.TrimSpace(string(output))
 	fm.Colors["green"].Printf("当前版本: %s\n", currentVersion)
-
-	// 选择下载源并获取最新版本
-	downloadSource := fm.selectDownloadSource()
-	if err := fm.getLatestVersion(downloadSource); err != nil {
-		fm.Colors["red"].Printf("获取最新版本失败: %v\n", err)
-		return
+	if fm.Component == ServerComponent && fm.loadInstalledConfig() {
+		fm.showConfigSummary()
 	}
 
-	fm.Colors["green"].Printf("最新版本: %s\n", fm.SystemInfo.FrpsVersion)
+	// 选择下载源并确定目标版本，指定了 --version 或 --from-archive 时不再获取最新版本
+	var mirror *Mirror
+	if opts.FromArchive != "" {
+		fm.Offline = true
+		if err := fm.inspectArchive(&opts.DownloadOptions); err != nil {
+			fm.Colors["red"].Printf("%v\n", err)
+			return
+		}
+	} else {
+		var err error
+		if mirror, err = fm.chooseDownloadSource(ctx, &opts.DownloadOptions); err != nil {
+			fm.Colors["red"].Printf("%v\n", err)
+			return
+		}
+		if err := fm.resolveVersion(ctx, mirror, &opts.DownloadOptions); err != nil {
+			fm.Colors["red"].Printf("获取%s失败: %v\n", versionTarget(&opts.DownloadOptions), err)
+			return
+		}
+	}
 
-	// 比较版本
-	if strings.Contains(currentVersion, fm.SystemInfo.FrpsVersion) {
-		fm.Colors["yellow"].Println("已经是最新版本，无需更新。")
+	// 比较版本，frps/frpc --version 只输出版本号
+	if strings.TrimPrefix(currentVersion, "v") == fm.SystemInfo.FrpsVersion {
+		if opts.Version != "" || opts.FromArchive != "" {
+			fm.Colors["yellow"].Printf("当前已是版本 %s，无需更新。\n", fm.SystemInfo.FrpsVersion)
+		} else {
+			fm.Colors["yellow"].Println("已经是最新版本，无需更新。")
+		}
 		return
 	}
 
-	fm.Colors["green"].Println("发现新版本，开始更新...")
+	if opts.Version != "" || opts.FromArchive != "" {
+		fm.Colors["green"].Printf("开始切换到版本 %s...\n", fm.SystemInfo.FrpsVersion)
+	} else {
+		fm.Colors["green"].Printf("发现新版本 %s，开始更新...\n", fm.SystemInfo.FrpsVersion)
+	}
 
 	// 停止服务
 	if fm.isInstalled() {
-		cmd := exec.Command(InitScript, "stop")
-		cmd.Run()
+		fm.Service.Stop()
 	}
 
-	// 备份当前二进制文件
+	// 将当前二进制文件移动为备份，安装新版本失败时恢复
 	backupPath := binaryPath + ".backup"
-	if err := exec.Command("cp", binaryPath, backupPath).Run(); err != nil {
-		fm.Colors["yellow"].Printf("备份当前版本失败: %v\n", err)
+	if err := os.Rename(binaryPath, backupPath); err != nil {
+		fm.Colors["red"].Printf("备份当前版本失败: %v\n", err)
+		return
 	}
 
 	// 下载新版本
-	if err := fm.downloadAndInstallBinary(downloadSource); err != nil {
+	if err := fm.downloadAndInstallBinary(ctx, mirror, &opts.DownloadOptions); err != nil {
 		fm.Colors["red"].Printf("下载新版本失败: %v\n", err)
 		// 恢复备份
-		if _, err := os.Stat(backupPath); err == nil {
-			exec.Command("mv", backupPath, binaryPath).Run()
+		if err := os.Rename(backupPath, binaryPath); err != nil {
+			fm.Colors["red"].Printf("恢复备份失败: %v，备份文件位于 %s\n", err, backupPath)
 		}
 		return
 	}
 
-	// 更新初始化脚本
-	if err := fm.downloadInitScript(); err != nil {
-		fm.Colors["yellow"].Printf("更新初始化脚本失败: %v\n", err)
+	// 重新设置服务，非 systemd 系统会同时更新初始化脚本
+	if err := fm.setupService(ctx); err != nil {
+		fm.Colors["yellow"].Printf("设置服务失败: %v\n", err)
 	}
 
-	// 重新设置服务
-	fm.setupService()
-
 	// 启动服务
 	if err := fm.startService(); err != nil {
 		fm.Colors["red"].Printf("启动服务失败: %v\n", err)
@@ -373,15 +424,16 @@ func (fm *FrpsManager) Update() {
 	if err == nil {
 		fm.Colors["green"].Printf("更新完成！新版本: %s\n", strings.TrimSpace(string(output)))
 	} else {
-		fm.Colors["green"].Println("frps 更新成功！")
+		fm.Colors["green"].Printf("%s 更新成功！\n", fm.Component.Name)
 	}
 }
 
 // ImportConfig 导入用户指定的配置文件
-func (fm *FrpsManager) ImportConfig(configPath string) {
+func (fm *FrpsManager) ImportConfig(opts *ImportOptions) {
 	if !fm.checkRoot() {
 		return
 	}
+	configPath := opts.Path
 
 	fm.showBanner()
 	
@@ -402,53 +454,52 @@ func (fm *FrpsManager) ImportConfig(configPath string) {
 	}
 	file.Close()
 	
-	// 验证配置文件格式（基本检查）
-	if err := fm.validateConfigFile(configPath); err != nil {
-		fm.Colors["red"].Printf("错误：配置文件格式验证失败: %v\n", err)
+	// 按 frps 的配置项检查语法、类型与取值范围
+	if err := fm.verifyConfig(configPath); err != nil {
+		fm.Colors["red"].Printf("错误：配置文件验证失败:\n%v\n", err)
 		return
 	}
 	
 	fm.Colors["green"].Println("✓ 配置文件验证通过")
 	
 	// 目标配置文件路径
-	targetConfigPath := filepath.Join(ProgramDir, ConfigFile)
+	targetConfigPath := fm.Component.ConfigPath()
 	
 	// 检查目标目录是否存在，不存在则创建
-	if err := os.MkdirAll(ProgramDir, 0755); err != nil {
+	if err := os.MkdirAll(fm.Component.Dir, 0755); err != nil {
 		fm.Colors["red"].Printf("错误：创建目录失败: %v\n", err)
 		return
 	}
 	
-	// 备份现有配置文件（如果存在）
-	if _, err := os.Stat(targetConfigPath); err == nil {
-		backupPath := targetConfigPath + ".backup." + fmt.Sprintf("%d", os.Getpid())
-		if err := fm.copyFile(targetConfigPath, backupPath); err != nil {
-			fm.Colors["yellow"].Printf("警告：备份现有配置文件失败: %v\n", err)
-		} else {
-			fm.Colors["green"].Printf("✓ 已备份现有配置文件到: %s\n", backupPath)
-		}
-	}
+	// 保存现有配置文件为历史版本，导入有误时可以回滚
+	fm.recordExistingConfig()
 	
-	// 复制用户配置文件到目标位置
-	if err := fm.copyFile(configPath, targetConfigPath); err != nil {
+	// 复制用户配置文件到目标位置，配置中包含 token，只允许服务用户读取
+	if opts.SecretsEnv {
+		content, err := os.ReadFile(configPath)
+		if err == nil {
+			content, err = fm.moveSecretsToEnv(content)
+		}
+		if err == nil {
+			err = writeFileAtomic(targetConfigPath, content, secretFileMode)
+		}
+		if err != nil {
+			fm.Colors["red"].Printf("错误：导入配置文件失败: %v\n", err)
+			return
+		}
+	} else if err := fm.copyFile(configPath, targetConfigPath, secretFileMode); err != nil {
 		fm.Colors["red"].Printf("错误：复制配置文件失败: %v\n", err)
 		return
 	}
 	
-	// 设置文件权限
-	if err := os.Chmod(targetConfigPath, 0644); err != nil {
-		fm.Colors["yellow"].Printf("警告：设置文件权限失败: %v\n", err)
-	}
-	
 	fm.Colors["green"].Printf("✓ 配置文件已成功导入到: %s\n", targetConfigPath)
+	fm.recordConfig("import-config " + configPath)
+	if fm.loadInstalledConfig() {
+		fm.showConfigSummary()
+	}
 	
 	// 询问是否重启服务
-	fmt.Print("是否重启 frps 服务以应用新配置？(y/n): ")
-	reader := bufio.NewReader(os.Stdin)
-	choice, _ := reader.ReadString('\n')
-	choice = strings.TrimSpace(strings.ToLower(choice))
-	
-	if choice == "y" || choice == "yes" {
+	if fm.confirm("是否重启 frps 服务以应用新配置？(y/n): ") {
 		if fm.isInstalled() {
 			fm.Restart()
 		} else {
@@ -459,45 +510,20 @@ func (fm *FrpsManager) ImportConfig(configPath string) {
 	fmt.Println("配置文件导入完成！")
 }
 
-// validateConfigFile 验证配置文件格式
-func (fm *FrpsManager) validateConfigFile(configPath string) error {
-	content, err := os.ReadFile(configPath)
+// copyFile 复制文件，先复制到临时文件，完成后才替换目标文件
+func (fm *FrpsManager) copyFile(src, dst string, perm os.FileMode) error {
+	source, err := os.Open(src)
 	if err != nil {
-		return fmt.Errorf("读取文件失败: %v", err)
-	}
-	
-	configStr := string(content)
-	
-	// 基本的TOML格式检查
-	if !strings.Contains(configStr, "bindPort") && !strings.Contains(configStr, "bind_port") {
-		return fmt.Errorf("配置文件缺少必要的 bindPort 或 bind_port 配置")
-	}
-	
-	// 检查文件是否为空
-	if len(strings.TrimSpace(configStr)) == 0 {
-		return fmt.Errorf("配置文件为空")
+		return err
 	}
-	
-	// 可以添加更多的验证逻辑
-	// 比如检查TOML语法，检查必要的配置项等
-	
-	return nil
-}
+	defer source.Close()
 
-// copyFile 复制文件
-func (fm *FrpsManager) copyFile(src, dst string) error {
-	sourceFile, err := os.Open(src)
-	if err != nil {
+	tmp, err := createTempFile(dst, perm, func(f *os.File) error {
+		_, err := f.ReadFrom(source)
 		return err
-	}
-	defer sourceFile.Close()
-	
-	destFile, err := os.Create(dst)
+	})
 	if err != nil {
 		return err
 	}
-	defer destFile.Close()
-	
-	_, err = destFile.ReadFrom(sourceFile)
-	return err
-} 
\ No newline at end of file
+	return commitTempFile(tmp, dst)
+}
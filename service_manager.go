@@ -0,0 +1,131 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// ServiceManager 服务管理后端，负责当前组件服务的安装、开机启动、启停、查询与移除
+type ServiceManager interface {
+	// Name 后端名称
+	Name() string
+	// Installed 服务定义是否已经存在
+	Installed() bool
+	// Install 写入服务定义
+	Install(ctx context.Context) error
+	// Enable 设置开机启动
+	Enable() error
+	Start() error
+	Stop() error
+	Restart() error
+	// Status 返回服务管理器给出的状态信息
+	Status() (string, error)
+	// Remove 停止服务并删除服务定义
+	Remove() error
+	// ControlCommand 返回执行指定操作的原生命令，用于提示用户
+	ControlCommand(action string) string
+}
+
+// serviceManagerNames 支持的服务管理后端
+var serviceManagerNames = []string{"systemd", "openrc", "runit", "s6", "supervisord", "sysv"}
+
+// newServiceManager 根据名称创建服务管理后端
+func newServiceManager(fm *FrpsManager, name string) (ServiceManager, error) {
+	switch name {
+	case "systemd":
+		return &systemdManager{fm: fm}, nil
+	case "openrc":
+		return &openrcManager{fm: fm}, nil
+	case "runit":
+		return &runitManager{fm: fm}, nil
+	case "s6":
+		return &s6Manager{fm: fm}, nil
+	case "supervisord":
+		return &supervisordManager{fm: fm}, nil
+	case "sysv":
+		return &sysvManager{fm: fm}, nil
+	default:
+		return nil, fmt.Errorf("未知的服务管理器: %s (可选 %s)", name, strings.Join(serviceManagerNames, ", "))
+	}
+}
+
+// detectServiceManager 检测当前运行的初始化系统，可通过 FRPS_ONEKEY_SERVICE_MANAGER 环境变量指定
+func detectServiceManager(fm *FrpsManager) ServiceManager {
+	if name := os.Getenv(EnvPrefix + "SERVICE_MANAGER"); name != "" {
+		if svc, err := newServiceManager(fm, strings.ToLower(name)); err == nil {
+			return svc
+		}
+		fm.Colors["yellow"].Printf("忽略无效的 %sSERVICE_MANAGER: %s\n", EnvPrefix, name)
+	}
+
+	if isSystemdRunning() {
+		return &systemdManager{fm: fm}
+	}
+
+	// 容器中通常由进程管理器直接作为 PID 1
+	switch pid1Name() {
+	case "s6-svscan":
+		return &s6Manager{fm: fm}
+	case "runit", "runsvdir":
+		return &runitManager{fm: fm}
+	case "supervisord":
+		return &supervisordManager{fm: fm}
+	case "openrc-init":
+		return &openrcManager{fm: fm}
+	}
+
+	if _, err := os.Stat("/run/openrc"); err == nil {
+		return &openrcManager{fm: fm}
+	}
+	if supervisordSocket() != "" {
+		if _, err := exec.LookPath("supervisorctl"); err == nil {
+			return &supervisordManager{fm: fm}
+		}
+	}
+
+	return &sysvManager{fm: fm}
+}
+
+// pid1Name 返回 PID 1 的进程名
+func pid1Name() string {
+	content, err := os.ReadFile("/proc/1/comm")
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(string(content))
+}
+
+// runCommand 执行命令，失败时附带命令输出
+func runCommand(name string, args ...string) (string, error) {
+	output, err := exec.Command(name, args...).CombinedOutput()
+	out := strings.TrimSpace(string(output))
+	if err != nil {
+		cmdline := strings.TrimSpace(name + " " + strings.Join(args, " "))
+		if out == "" {
+			return out, fmt.Errorf("%s 失败: %v", cmdline, err)
+		}
+		return out, fmt.Errorf("%s 失败: %v: %s", cmdline, err, out)
+	}
+	return out, nil
+}
+
+// writeServiceFile 写入服务定义文件，自动创建上级目录
+func writeServiceFile(path, content string, perm os.FileMode) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("创建目录 %s 失败: %v", filepath.Dir(path), err)
+	}
+	if err := writeFileAtomic(path, []byte(content), perm); err != nil {
+		return fmt.Errorf("写入 %s 失败: %v", path, err)
+	}
+	return nil
+}
+
+// fileExists 检查文件是否存在
+func fileExists(path string) bool {
+	_, err := os.Lstat(path)
+	return err == nil
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// supervisordManager 通过 supervisorctl 管理 frps 或 frpc
+type supervisordManager struct {
+	fm *FrpsManager
+}
+
+// supervisordSocket 返回 supervisord 的控制 socket，未运行时返回空字符串
+func supervisordSocket() string {
+	for _, path := range []string{"/var/run/supervisor.sock", "/run/supervisor.sock", "/tmp/supervisor.sock", "/run/supervisor/supervisor.sock"} {
+		if fileExists(path) {
+			return path
+		}
+	}
+	return ""
+}
+
+// confPath 返回 program 配置文件路径，Debian 系使用 conf.d/*.conf，RHEL 系使用 supervisord.d/*.ini
+func (s *supervisordManager) confPath() string {
+	if stat, err := os.Stat("/etc/supervisord.d"); err == nil && stat.IsDir() {
+		return filepath.Join("/etc/supervisord.d", s.fm.Component.Name+".ini")
+	}
+	return filepath.Join("/etc/supervisor/conf.d", s.fm.Component.Name+".conf")
+}
+
+// confContent 生成 program 配置
+func (s *supervisordManager) confContent() string {
+	c := s.fm.Component
+	return fmt.Sprintf(`[program:%s]
+command=%s
+directory=%s
+autostart=true
+autorestart=true
+startsecs=3
+stopsignal=TERM
+redirect_stderr=true
+stdout_logfile=/var/log/%s.supervisor.log
+`, c.Name, c.ServiceCommand(), c.Dir, c.Name)
+}
+
+func (s *supervisordManager) Name() string {
+	return "supervisord"
+}
+
+func (s *supervisordManager) Installed() bool {
+	return fileExists(s.confPath())
+}
+
+func (s *supervisordManager) Install(ctx context.Context) error {
+	s.fm.Colors["green"].Printf("正在写入 supervisord 配置 %s...\n", s.confPath())
+	return writeServiceFile(s.confPath(), s.confContent(), 0644)
+}
+
+func (s *supervisordManager) Enable() error {
+	// autostart=true 保证 supervisord 启动时拉起服务，这里只需加载新配置
+	if _, err := runCommand("supervisorctl", "reread"); err != nil {
+		return err
+	}
+	_, err := runCommand("supervisorctl", "update", s.fm.Component.Name)
+	return err
+}
+
+func (s *supervisordManager) Start() error {
+	_, err := runCommand("supervisorctl", "start", s.fm.Component.Name)
+	return err
+}
+
+func (s *supervisordManager) Stop() error {
+	_, err := runCommand("supervisorctl", "stop", s.fm.Component.Name)
+	return err
+}
+
+func (s *supervisordManager) Restart() error {
+	_, err := runCommand("supervisorctl", "restart", s.fm.Component.Name)
+	return err
+}
+
+func (s *supervisordManager) Status() (string, error) {
+	output, err := exec.Command("supervisorctl", "status", s.fm.Component.Name).CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+func (s *supervisordManager) Remove() error {
+	runCommand("supervisorctl", "stop", s.fm.Component.Name)
+	if err := os.Remove(s.confPath()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除 %s 失败: %v", s.confPath(), err)
+	}
+	runCommand("supervisorctl", "reread")
+	_, err := runCommand("supervisorctl", "update")
+	return err
+}
+
+func (s *supervisordManager) ControlCommand(action string) string {
+	return fmt.Sprintf("supervisorctl %s %s", action, s.fm.Component.Name)
+}
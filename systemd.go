@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// isSystemdRunning 检查 systemd 是否为 PID 1，判断方式与 sd_booted() 相同
+func isSystemdRunning() bool {
+	stat, err := os.Stat("/run/systemd/system")
+	return err == nil && stat.IsDir()
+}
+
+// systemdManager 通过 systemctl 管理 frps.service 或 frpc.service
+type systemdManager struct {
+	fm *FrpsManager
+}
+
+// unitFile 返回服务单元文件路径
+func (s *systemdManager) unitFile() string {
+	return "/etc/systemd/system/" + s.fm.Component.Name + ".service"
+}
+
+func (s *systemdManager) Name() string {
+	return "systemd"
+}
+
+func (s *systemdManager) Installed() bool {
+	return fileExists(s.unitFile())
+}
+
+// unitContent 生成服务单元内容，存在环境变量文件时由 systemd 加载
+func (s *systemdManager) unitContent() string {
+	c := s.fm.Component
+	environment := ""
+	if fileExists(c.EnvFile()) {
+		environment = "EnvironmentFile=" + c.EnvFile() + "\n"
+	}
+	return fmt.Sprintf(`[Unit]
+Description=%s (%s)
+Documentation=https://github.com/fatedier/frp
+Wants=network-online.target
+After=network-online.target
+
+[Service]
+Type=simple
+WorkingDirectory=%s
+%sExecStart=%s
+Restart=on-failure
+RestartSec=5s
+LimitNOFILE=1048576
+
+[Install]
+WantedBy=multi-user.target
+`, c.Description, c.Name, c.Dir, environment, c.CommandLine())
+}
+
+func (s *systemdManager) Install(ctx context.Context) error {
+	s.fm.Colors["green"].Printf("正在写入 systemd 服务文件 %s...\n", s.unitFile())
+	return writeServiceFile(s.unitFile(), s.unitContent(), 0644)
+}
+
+func (s *systemdManager) Enable() error {
+	if _, err := runCommand("systemctl", "daemon-reload"); err != nil {
+		return err
+	}
+	_, err := runCommand("systemctl", "enable", s.fm.Component.Name)
+	return err
+}
+
+func (s *systemdManager) Start() error {
+	_, err := runCommand("systemctl", "start", s.fm.Component.Name)
+	return err
+}
+
+func (s *systemdManager) Stop() error {
+	_, err := runCommand("systemctl", "stop", s.fm.Component.Name)
+	return err
+}
+
+func (s *systemdManager) Restart() error {
+	_, err := runCommand("systemctl", "restart", s.fm.Component.Name)
+	return err
+}
+
+func (s *systemdManager) Status() (string, error) {
+	// 服务未运行时 systemctl status 返回非零，输出仍然有效
+	output, _ := exec.Command("systemctl", "status", s.fm.Component.Name, "--no-pager").CombinedOutput()
+	return strings.TrimRight(string(output), "\n"), nil
+}
+
+func (s *systemdManager) Remove() error {
+	runCommand("systemctl", "disable", "--now", s.fm.Component.Name)
+
+	if err := os.Remove(s.unitFile()); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除服务文件失败: %v", err)
+	}
+
+	// 旧版本安装的 init.d 脚本会被 systemd-sysv-generator 重新生成为 frps.service，一并清理
+	if fileExists(s.fm.Component.InitScript()) {
+		(&sysvManager{fm: s.fm}).removeFiles()
+	}
+
+	_, err := runCommand("systemctl", "daemon-reload")
+	return err
+}
+
+func (s *systemdManager) ControlCommand(action string) string {
+	return fmt.Sprintf("systemctl %s %s", action, s.fm.Component.Name)
+}
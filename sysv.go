@@ -0,0 +1,103 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// sysvManager 通过 /etc/init.d 脚本管理 frps 或 frpc，作为没有其他初始化系统时的回退方案
+type sysvManager struct {
+	fm *FrpsManager
+}
+
+func (s *sysvManager) Name() string {
+	return "sysv"
+}
+
+func (s *sysvManager) Installed() bool {
+	return fileExists(s.fm.Component.InitScript())
+}
+
+func (s *sysvManager) Install(ctx context.Context) error {
+	// 使用内置脚本，每次安装与更新时替换已有的（可能是旧版本或第三方的）脚本
+	c := s.fm.Component
+	return writeServiceFile(c.InitScript(), c.initScriptContent(), 0755)
+}
+
+func (s *sysvManager) Enable() error {
+	// 按系统提供的工具注册开机启动
+	if _, err := exec.LookPath("chkconfig"); err == nil {
+		if _, err := runCommand("chkconfig", "--add", s.fm.Component.Name); err != nil {
+			return fmt.Errorf("设置开机启动失败: %v", err)
+		}
+	} else if _, err := exec.LookPath("update-rc.d"); err == nil {
+		if _, err := runCommand("update-rc.d", "-f", s.fm.Component.Name, "defaults"); err != nil {
+			return fmt.Errorf("设置开机启动失败: %v", err)
+		}
+	} else {
+		return fmt.Errorf("不支持的操作系统: %s，未找到 chkconfig 或 update-rc.d", s.fm.SystemInfo.DisplayName())
+	}
+
+	// 创建软链接
+	linkPath := "/usr/bin/" + s.fm.Component.Name
+	if err := os.Remove(linkPath); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("删除旧的软链接失败: %v", err)
+	}
+
+	if err := os.Symlink(s.fm.Component.InitScript(), linkPath); err != nil {
+		return fmt.Errorf("创建软链接失败: %v", err)
+	}
+
+	return nil
+}
+
+func (s *sysvManager) Start() error {
+	_, err := runCommand(s.fm.Component.InitScript(), "start")
+	return err
+}
+
+func (s *sysvManager) Stop() error {
+	_, err := runCommand(s.fm.Component.InitScript(), "stop")
+	return err
+}
+
+func (s *sysvManager) Restart() error {
+	_, err := runCommand(s.fm.Component.InitScript(), "restart")
+	return err
+}
+
+func (s *sysvManager) Status() (string, error) {
+	output, err := exec.Command(s.fm.Component.InitScript(), "status").CombinedOutput()
+	return strings.TrimSpace(string(output)), err
+}
+
+func (s *sysvManager) Remove() error {
+	if s.Installed() {
+		runCommand(s.fm.Component.InitScript(), "stop")
+	}
+
+	if _, err := exec.LookPath("chkconfig"); err == nil {
+		runCommand("chkconfig", "--del", s.fm.Component.Name)
+	} else if _, err := exec.LookPath("update-rc.d"); err == nil {
+		runCommand("update-rc.d", "-f", s.fm.Component.Name, "remove")
+	}
+
+	return s.removeFiles()
+}
+
+// removeFiles 删除初始化脚本、pid 文件与软链接
+func (s *sysvManager) removeFiles() error {
+	for _, file := range []string{s.fm.Component.InitScript(), "/var/run/" + s.fm.Component.Name + ".pid", "/usr/bin/" + s.fm.Component.Name} {
+		if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("删除 %s 失败: %v", file, err)
+		}
+	}
+	return nil
+}
+
+func (s *sysvManager) ControlCommand(action string) string {
+	return fmt.Sprintf("%s %s", s.fm.Component.Name, action)
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// tomlHeaderRe 匹配表头 [name] 或数组表头 [[name]]，允许行尾注释
+var tomlHeaderRe = regexp.MustCompile(`^\s*(\[\[?)\s*([A-Za-z0-9_.\-"' ]+?)\s*\]\]?\s*(#.*)?$`)
+
+// tomlKeyRe 匹配键值行，键可以带点
+var tomlKeyRe = regexp.MustCompile(`^\s*([A-Za-z0-9_\-]+(?:\s*\.\s*[A-Za-z0-9_\-]+)*)\s*=\s*(.*)$`)
+
+// tomlDoc 按行保存的 TOML 文档，修改时保留原有的注释与格式
+type tomlDoc struct {
+	lines []string
+}
+
+// tomlTable 文档中的一个表，Start 为表头所在行，End 为表结束后的第一行
+type tomlTable struct {
+	Name  string
+	Array bool
+	Start int
+	End   int
+}
+
+// parseTOMLDoc 将内容拆分为行
+func parseTOMLDoc(content []byte) *tomlDoc {
+	text := strings.TrimSuffix(string(content), "\n")
+	if text == "" {
+		return &tomlDoc{}
+	}
+	return &tomlDoc{lines: strings.Split(text, "\n")}
+}
+
+// Bytes 返回文档内容，以换行结尾
+func (d *tomlDoc) Bytes() []byte {
+	if len(d.lines) == 0 {
+		return nil
+	}
+	return []byte(strings.Join(d.lines, "\n") + "\n")
+}
+
+// headerLines 返回每一行是否为表头，多行字符串中的内容不计入
+func (d *tomlDoc) headerLines() []bool {
+	headers, _ := d.scan()
+	return headers
+}
+
+// scan 标记每一行是否为表头，以及是否为多行值 (多行数组、多行字符串) 的后续行
+func (d *tomlDoc) scan() (headers, inValues []bool) {
+	headers = make([]bool, len(d.lines))
+	inValues = make([]bool, len(d.lines))
+	for i := 0; i < len(d.lines); i++ {
+		if tomlHeaderRe.MatchString(d.lines[i]) {
+			headers[i] = true
+			continue
+		}
+		end := d.valueEnd(i)
+		for j := i + 1; j < end; j++ {
+			inValues[j] = true
+		}
+		i = end - 1
+	}
+	return headers, inValues
+}
+
+// valueEnd 返回第 i 行的键值结束后的第一行。值中的括号未闭合或多行字符串未结束时延续到后续行，
+// 不是键值行时返回 i+1
+func (d *tomlDoc) valueEnd(i int) int {
+	m := tomlKeyRe.FindStringSubmatch(d.lines[i])
+	if m == nil {
+		return i + 1
+	}
+	text, depth, quote := m[2], 0, ""
+	for {
+		for j := 0; j < len(text); j++ {
+			if quote != "" {
+				if text[j] == '\\' && quote[0] == '"' {
+					j++
+				} else if strings.HasPrefix(text[j:], quote) {
+					j += len(quote) - 1
+					quote = ""
+				}
+				continue
+			}
+			switch c := text[j]; c {
+			case '#':
+				j = len(text)
+			case '[', '{':
+				depth++
+			case ']', '}':
+				depth--
+			case '"', '\'':
+				quote = string(c)
+				if strings.HasPrefix(text[j:], strings.Repeat(quote, 3)) {
+					quote = strings.Repeat(quote, 3)
+					j += 2
+				}
+			}
+		}
+		// 单行字符串不能跨行
+		if len(quote) == 1 {
+			quote = ""
+		}
+		if (depth <= 0 && quote == "") || i+1 >= len(d.lines) {
+			return i + 1
+		}
+		i++
+		text = d.lines[i]
+	}
+}
+
+// valueText 返回第 i 行的键的值，多行值包含后续行
+func (d *tomlDoc) valueText(i int) string {
+	m := tomlKeyRe.FindStringSubmatch(d.lines[i])
+	if m == nil {
+		return ""
+	}
+	return strings.Join(append([]string{m[2]}, d.lines[i+1:d.valueEnd(i)]...), "\n")
+}
+
+// tables 返回文档中的所有表，表的结束位置不包含紧贴在下一个表头之前的注释
+func (d *tomlDoc) tables() []tomlTable {
+	headers := d.headerLines()
+	var tables []tomlTable
+	for i, isHeader := range headers {
+		if !isHeader {
+			continue
+		}
+		m := tomlHeaderRe.FindStringSubmatch(d.lines[i])
+		if n := len(tables); n > 0 {
+			tables[n-1].End = d.commentStart(i)
+		}
+		tables = append(tables, tomlTable{
+			Name:  strings.ReplaceAll(m[2], " ", ""),
+			Array: m[1] == "[[",
+			Start: i,
+			End:   len(d.lines),
+		})
+	}
+	return tables
+}
+
+// commentStart 返回紧贴在第 i 行之前的注释块的起始行
+func (d *tomlDoc) commentStart(i int) int {
+	for i > 0 && strings.HasPrefix(strings.TrimSpace(d.lines[i-1]), "#") {
+		i--
+	}
+	return i
+}
+
+// value 读取表中键的值，表头行之后到表结束之间查找，未找到时 ok 为 false
+func (d *tomlDoc) value(t tomlTable, key string) (interface{}, bool) {
+	for i := t.Start + 1; i < t.End; i++ {
+		m := tomlKeyRe.FindStringSubmatch(d.lines[i])
+		if m == nil || strings.ReplaceAll(m[1], " ", "") != key {
+			continue
+		}
+		v, err := decodeTOMLValue(d.valueText(i))
+		if err != nil {
+			return nil, false
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+// remove 删除 [start, end) 行，并合并删除处多余的空行
+func (d *tomlDoc) remove(start, end int) {
+	lines := append(append([]string{}, d.lines[:start]...), d.lines[end:]...)
+	for start > 0 && start < len(lines) && strings.TrimSpace(lines[start-1]) == "" && strings.TrimSpace(lines[start]) == "" {
+		lines = append(lines[:start], lines[start+1:]...)
+	}
+	for len(lines) > 0 && strings.TrimSpace(lines[len(lines)-1]) == "" {
+		lines = lines[:len(lines)-1]
+	}
+	d.lines = lines
+}
+
+// appendBlock 在文档末尾追加一段内容，与前文之间空一行
+func (d *tomlDoc) appendBlock(block []string) {
+	for len(d.lines) > 0 && strings.TrimSpace(d.lines[len(d.lines)-1]) == "" {
+		d.lines = d.lines[:len(d.lines)-1]
+	}
+	if len(d.lines) > 0 {
+		d.lines = append(d.lines, "")
+	}
+	d.lines = append(d.lines, block...)
+}
+
+// tomlKeyLine 文档中的一个表头或键，Index 为同名数组表中的序号，表头的 Key 为空
+type tomlKeyLine struct {
+	Table  string
+	Array  bool
+	Index  int
+	Key    string
+	Line   int
+	Column int
+}
+
+// Full 返回带表名的完整键名
+func (k tomlKeyLine) Full() string {
+	if k.Table == "" {
+		return k.Key
+	}
+	return k.Table + "." + k.Key
+}
+
+// keyLines 返回文档中所有的表头与键及其行列
+func (d *tomlDoc) keyLines() []tomlKeyLine {
+	headers, inValues := d.scan()
+	var keys []tomlKeyLine
+	counts := make(map[string]int)
+	table, array, index := "", false, 0
+	for i, line := range d.lines {
+		column := len([]rune(line)) - len([]rune(strings.TrimLeft(line, " \t"))) + 1
+		switch {
+		case headers[i]:
+			m := tomlHeaderRe.FindStringSubmatch(line)
+			table, array = strings.ReplaceAll(m[2], " ", ""), m[1] == "[["
+			index = counts[table]
+			counts[table]++
+			keys = append(keys, tomlKeyLine{Table: table, Array: array, Index: index, Line: i + 1, Column: column})
+		case inValues[i]:
+		default:
+			if m := tomlKeyRe.FindStringSubmatch(line); m != nil {
+				keys = append(keys, tomlKeyLine{Table: table, Array: array, Index: index, Key: strings.ReplaceAll(m[1], " ", ""), Line: i + 1, Column: column})
+			}
+		}
+	}
+	return keys
+}
+
+// position 返回键所在的行列，name[i] 表示数组的第 i 项，找不到时逐级返回父级的位置，都找不到时返回 0
+func (d *tomlDoc) position(key string) (line, column int) {
+	keys := d.keyLines()
+	for key != "" {
+		prefix, rest, index := key, "", -1
+		if open := strings.Index(key, "["); open >= 0 {
+			if end := strings.Index(key[open:], "]"); end > 0 {
+				prefix = key[:open]
+				index, _ = strconv.Atoi(key[open+1 : open+end])
+				rest = strings.TrimPrefix(key[open+end+1:], ".")
+				// 数组表中的数组值定位到键所在的行
+				if i := strings.Index(rest, "["); i >= 0 {
+					rest = rest[:i]
+				}
+			}
+		}
+
+		for _, k := range keys {
+			if index >= 0 && k.Table == prefix && k.Index == index && k.Key == rest {
+				return k.Line, k.Column
+			}
+			if index < 0 && k.Key != "" && k.Full() == key {
+				return k.Line, k.Column
+			}
+		}
+		// 数组表定位到对应的表头，内联数组定位到键本身，子表定位到最先出现的相关键
+		for _, k := range keys {
+			if index >= 0 {
+				if (k.Key == "" && k.Table == prefix && k.Index == index) || (k.Key != "" && k.Full() == prefix) {
+					return k.Line, k.Column
+				}
+				continue
+			}
+			if k.Key != "" && (k.Full() == prefix || strings.HasPrefix(k.Full(), prefix+".")) {
+				return k.Line, k.Column
+			}
+			if k.Key == "" && k.Table == prefix {
+				return k.Line, k.Column
+			}
+		}
+
+		if i := strings.LastIndexAny(prefix, ".["); i >= 0 {
+			key = prefix[:i]
+		} else {
+			key = ""
+		}
+	}
+	return 0, 0
+}
+
+// lookup 返回普通表 (不含数组表) 中键所在的行号，从 0 开始，未找到时返回 -1
+func (d *tomlDoc) lookup(key string) int {
+	for _, k := range d.keyLines() {
+		if k.Key != "" && !k.Array && k.Full() == key {
+			return k.Line - 1
+		}
+	}
+	return -1
+}
+
+// set 设置键的值，value 为 TOML 格式的值。已有的键原地替换 (多行值整体替换) 并保留缩进，
+// 否则追加到所属表的末尾，没有所属的表时以点分隔的完整键名追加到根表末尾
+func (d *tomlDoc) set(key, value string) {
+	if i := d.lookup(key); i >= 0 {
+		line := d.lines[i]
+		indent := line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+		name := strings.TrimSpace(tomlKeyRe.FindStringSubmatch(line)[1])
+		lines := append(append([]string{}, d.lines[:i]...), indent+name+" = "+value)
+		d.lines = append(lines, d.lines[d.valueEnd(i):]...)
+		return
+	}
+
+	// 根表结束于第一个表头之前的注释与空行
+	tables := d.tables()
+	at, name, matched := len(d.lines), key, ""
+	if len(tables) > 0 {
+		at = d.commentStart(tables[0].Start)
+	}
+	// 有多个表匹配时选择最深的一个，如 [webServer.tls] 优先于 [webServer]
+	for _, t := range tables {
+		if !t.Array && strings.HasPrefix(key, t.Name+".") && len(t.Name) > len(matched) {
+			at, name, matched = t.End, strings.TrimPrefix(key, t.Name+"."), t.Name
+		}
+	}
+	for at > 0 && strings.TrimSpace(d.lines[at-1]) == "" {
+		at--
+	}
+
+	lines := append(append([]string{}, d.lines[:at]...), name+" = "+value)
+	d.lines = append(lines, d.lines[at:]...)
+}
+
+// unset 删除键所在的行，多行值的后续行一并删除，返回键是否存在
+func (d *tomlDoc) unset(key string) bool {
+	i := d.lookup(key)
+	if i < 0 {
+		return false
+	}
+	d.lines = append(d.lines[:i], d.lines[d.valueEnd(i):]...)
+	return true
+}
@@ -0,0 +1,128 @@
+package main
+
+import "testing"
+
+const testTOMLDoc = `# frps.toml
+bindPort = 7000
+auth.token = "abc" # inline comment
+allowPorts = [
+  { start = 2000, end = 3000 },
+  { single = 3001 },
+]
+note = """
+x = 1
+[not.a.table]
+"""
+
+[webServer]
+port = 7500
+
+[webServer.tls]
+certFile = "/etc/frp/cert.pem"
+
+[[httpPlugins]]
+name = "p"
+`
+
+func TestTOMLDocLookup(t *testing.T) {
+	doc := parseTOMLDoc([]byte(testTOMLDoc))
+	tests := []struct {
+		key  string
+		line int
+	}{
+		{"bindPort", 1},
+		{"auth.token", 2},
+		{"allowPorts", 3},
+		{"note", 7},
+		{"webServer.port", 13},
+		{"webServer.tls.certFile", 16},
+		// 多行值中的内容不是键
+		{"x", -1},
+		{"start", -1},
+		// 数组表中的键不能按普通键查找
+		{"httpPlugins.name", -1},
+		{"missing", -1},
+	}
+	for _, tt := range tests {
+		if got := doc.lookup(tt.key); got != tt.line {
+			t.Errorf("lookup(%q) = %d, want %d", tt.key, got, tt.line)
+		}
+	}
+	if tables := doc.tables(); len(tables) != 3 {
+		t.Errorf("tables() = %+v, want 3 tables", tables)
+	}
+}
+
+func TestTOMLDocSet(t *testing.T) {
+	tests := []struct {
+		name  string
+		src   string
+		key   string
+		value string
+		want  string
+	}{
+		{"replace", "bindPort = 7000\nlog.level = \"info\"\n", "bindPort", "7001",
+			"bindPort = 7001\nlog.level = \"info\"\n"},
+		{"keep indent", "[webServer]\n  port = 7500\n", "webServer.port", "7501",
+			"[webServer]\n  port = 7501\n"},
+		{"append to root before tables", "bindPort = 7000\n\n# dashboard\n[webServer]\nport = 7500\n", "log.level", `"debug"`,
+			"bindPort = 7000\nlog.level = \"debug\"\n\n# dashboard\n[webServer]\nport = 7500\n"},
+		{"append to deepest table", "[webServer]\nport = 7500\n\n[webServer.tls]\ncertFile = \"a\"\n", "webServer.tls.keyFile", `"b"`,
+			"[webServer]\nport = 7500\n\n[webServer.tls]\ncertFile = \"a\"\nkeyFile = \"b\"\n"},
+		{"replace multi-line array", "allowPorts = [\n  { start = 2000, end = 3000 }, # ]\n  { single = 3001 },\n]\nbindPort = 7000\n", "allowPorts", "[{ single = 4000 }]",
+			"allowPorts = [{ single = 4000 }]\nbindPort = 7000\n"},
+		{"replace multi-line string", "custom404Page = \"\"\"\na = [\n\"\"\"\nbindPort = 7000\n", "custom404Page", `"x"`,
+			"custom404Page = \"x\"\nbindPort = 7000\n"},
+		{"empty document", "", "bindPort", "7000", "bindPort = 7000\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseTOMLDoc([]byte(tt.src))
+			doc.set(tt.key, tt.value)
+			if got := string(doc.Bytes()); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOMLDocUnset(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		key  string
+		ok   bool
+		want string
+	}{
+		{"single line", "bindPort = 7000\nlog.level = \"info\"\n", "log.level", true, "bindPort = 7000\n"},
+		{"multi-line array", "allowPorts = [\n  { start = 2000, end = 3000 },\n]\nbindPort = 7000\n", "allowPorts", true, "bindPort = 7000\n"},
+		{"multi-line literal string", "note = '''\n]\n'''\nbindPort = 7000\n", "note", true, "bindPort = 7000\n"},
+		{"table key", "[webServer]\nport = 7500\nuser = \"admin\"\n", "webServer.user", true, "[webServer]\nport = 7500\n"},
+		{"missing", "bindPort = 7000\n", "log.level", false, "bindPort = 7000\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := parseTOMLDoc([]byte(tt.src))
+			if ok := doc.unset(tt.key); ok != tt.ok {
+				t.Errorf("unset(%q) = %v, want %v", tt.key, ok, tt.ok)
+			}
+			if got := string(doc.Bytes()); got != tt.want {
+				t.Errorf("got:\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTOMLDocValueText(t *testing.T) {
+	doc := parseTOMLDoc([]byte(testTOMLDoc))
+	if got, want := doc.valueText(doc.lookup("allowPorts")), "[\n  { start = 2000, end = 3000 },\n  { single = 3001 },\n]"; got != want {
+		t.Errorf("valueText(allowPorts) = %q, want %q", got, want)
+	}
+	value, err := decodeTOMLValue(doc.valueText(doc.lookup("allowPorts")))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := tomlValue(value); got != "[{ end = 3000, start = 2000 }, { single = 3001 }]" {
+		t.Errorf("decoded allowPorts = %s", got)
+	}
+}